@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/tousart/avitotest/internal/api"
+	"github.com/tousart/avitotest/internal/api/helpers"
 	"github.com/tousart/avitotest/internal/repository/postgres"
 	"github.com/tousart/avitotest/internal/server"
 	"github.com/tousart/avitotest/internal/usecase/service"
@@ -47,17 +48,40 @@ func main() {
 		log.Fatalf("failed to create users repository")
 	}
 
+	codeOwnersRepo, err := postgres.NewCodeOwnersRepository(address)
+	if err != nil {
+		log.Fatalf("failed to create code owners repository")
+	}
+
+	idempotencyRepo, err := postgres.NewIdempotencyRepository(address)
+	if err != nil {
+		log.Fatalf("failed to create idempotency repository")
+	}
+
 	// usecase
 
-	teamsService := service.NewTeamsService(teamsRepo)
+	reviewerSelector, err := service.NewReviewerSelector(os.Getenv("REVIEWER_STRATEGY"))
+	if err != nil {
+		log.Fatalf("failed to create reviewer selector: %v", err)
+	}
+
+	teamsService := service.NewTeamsService(teamsRepo, reviewerSelector)
+
+	usersService := service.NewUsersService(usersRepo, reviewerSelector)
 
-	usersService := service.NewUsersService(usersRepo)
+	pullRequestsService := service.NewPullRequestsService(pullRequestsRepo, codeOwnersRepo, reviewerSelector)
 
-	pullRequestsService := service.NewPullRequestsService(pullRequestsRepo)
+	codeOwnersService := service.NewCodeOwnersService(codeOwnersRepo)
+
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo)
 
 	// api
 
 	r := chi.NewRouter()
+	r.Use(helpers.Actor)
+
+	idempotencyAPI := api.CreateIdempotencyAPI(idempotencyService)
+	r.Use(idempotencyAPI.Middleware)
 
 	teamsAPI := api.CreateTeamsAPI(teamsService)
 	teamsAPI.WithTeamsHandlers(r)
@@ -68,6 +92,9 @@ func main() {
 	pullRequestsAPI := api.CreatePullRequestsAPI(pullRequestsService)
 	pullRequestsAPI.WithPullRequestsHandlers(r)
 
+	codeOwnersAPI := api.CreateCodeOwnersAPI(codeOwnersService)
+	codeOwnersAPI.WithCodeOwnersHandlers(r)
+
 	// Запуск сервера
 
 	serv := server.CreateAndRunServer(r, os.Getenv("SERVER_PORT"), errChan)
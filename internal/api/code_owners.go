@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tousart/avitotest/internal/api/helpers"
+	"github.com/tousart/avitotest/internal/api/types"
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/usecase"
+)
+
+type CodeOwners struct {
+	codeOwnersService usecase.CodeOwnersService
+}
+
+func CreateCodeOwnersAPI(codeOwnersService usecase.CodeOwnersService) *CodeOwners {
+	return &CodeOwners{
+		codeOwnersService: codeOwnersService,
+	}
+}
+
+func (c *CodeOwners) codeOwnersSetHandler(w http.ResponseWriter, r *http.Request) {
+	codeOwners, err := types.CreateCodeOwnersSetRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	errResp := c.codeOwnersService.CodeOwnersSet(r.Context(), codeOwners)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(codeOwners)
+}
+
+func (c *CodeOwners) codeOwnersGetHandler(w http.ResponseWriter, r *http.Request) {
+	var codeOwners models.CodeOwners
+
+	errResp := c.codeOwnersService.CodeOwnersGet(r.Context(), &codeOwners)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(codeOwners)
+}
+
+func (c *CodeOwners) WithCodeOwnersHandlers(r chi.Router) {
+	r.Route("/codeOwners", func(r chi.Router) {
+		r.Post("/set", c.codeOwnersSetHandler)
+		r.Get("/get", c.codeOwnersGetHandler)
+	})
+}
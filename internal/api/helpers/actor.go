@@ -0,0 +1,23 @@
+package helpers
+
+import (
+	"net/http"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+const ActorHeader = "X-Actor-ID"
+
+// Middleware, передающая инициатора запроса (заголовок X-Actor-ID, необязательный) в контекст.
+// Сервис сам не аутентифицирует инициатора и доверяет заголовку: его должен выставлять шлюз, который
+// проверил пользователя и удалил X-Actor-ID, пришедший от клиента. Без такого шлюза права лида
+// (ручной выбор ревьюеров) может получить любой клиент, подставив чужой идентификатор
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(models.WithActor(r.Context(), actor))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
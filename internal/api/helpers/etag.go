@@ -0,0 +1,11 @@
+package helpers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Версия пулл реквеста в заголовке ETag (сильный валидатор, "<version>")
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
@@ -22,6 +22,14 @@ func GetStatusError(code string) int {
 	switch code {
 	case codes.ErrTeamExists:
 		return http.StatusBadRequest
+	case codes.ErrTeamArchived:
+		return http.StatusConflict
+	case codes.ErrUserInOtherTeam:
+		return http.StatusConflict
+	case codes.ErrUserExists:
+		return http.StatusConflict
+	case codes.ErrPrimaryTeam:
+		return http.StatusConflict
 	case codes.ErrNotFound:
 		return http.StatusNotFound
 	case codes.ErrPRExists:
@@ -32,6 +40,22 @@ func GetStatusError(code string) int {
 		return http.StatusConflict
 	case codes.ErrNoCandidate:
 		return http.StatusConflict
+	case codes.ErrCapacityExceeded:
+		return http.StatusConflict
+	case codes.ErrNotEligible:
+		return http.StatusConflict
+	case codes.ErrInvalidTransition:
+		return http.StatusConflict
+	case codes.ErrMergeBlocked:
+		return http.StatusConflict
+	case codes.ErrVersionMismatch:
+		return http.StatusPreconditionFailed
+	case codes.ErrIdempotencyReused:
+		return http.StatusUnprocessableEntity
+	case codes.ErrIdempotencyBusy:
+		return http.StatusConflict
+	case codes.ErrForbidden:
+		return http.StatusForbidden
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/tousart/avitotest/internal/api/helpers"
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/usecase"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength  = 255
+	MaxIdempotentBodySize    = 1 << 20
+)
+
+type Idempotency struct {
+	idempotencyService usecase.IdempotencyService
+}
+
+func CreateIdempotencyAPI(idempotencyService usecase.IdempotencyService) *Idempotency {
+	return &Idempotency{
+		idempotencyService: idempotencyService,
+	}
+}
+
+// Middleware для POST запросов с заголовком Idempotency-Key: первый ответ на ключ сохраняется
+// и возвращается на повторные запросы с тем же ключом без повторного выполнения (ответы 5xx не сохраняются).
+// Тело таких запросов читается в память целиком, поэтому оно ограничено MaxIdempotentBodySize
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > MaxIdempotencyKeyLength {
+			helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "idempotency key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxIdempotentBodySize))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			helpers.WriteAPIError(w, http.StatusRequestEntityTooLarge, codes.ErrBadRequet, "request body is too large")
+			return
+		} else if err != nil {
+			helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		request := &models.IdempotentRequest{
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+		}
+
+		errResp := i.idempotencyService.IdempotencyBegin(r.Context(), request)
+		if errResp != nil {
+			status := helpers.GetStatusError(errResp.Code)
+			helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+			return
+		}
+
+		// Повтор: отдаем сохраненный ответ
+
+		if request.StatusCode != 0 {
+			for name, values := range request.Header {
+				w.Header()[name] = values
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(request.StatusCode)
+			w.Write(request.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Ответ сохраняется, даже если клиент уже отключился
+
+		ctx := context.WithoutCancel(r.Context())
+		if recorder.statusCode >= http.StatusInternalServerError {
+			if errResp := i.idempotencyService.IdempotencyRelease(ctx, request); errResp != nil {
+				log.Printf("api: Idempotency: release key %s: %s\n", key, errResp.Message)
+			}
+			return
+		}
+
+		request.StatusCode = recorder.statusCode
+		request.Header = recorder.Header().Clone()
+		request.Body = recorder.body.Bytes()
+		if errResp := i.idempotencyService.IdempotencySave(ctx, request); errResp != nil {
+			log.Printf("api: Idempotency: save key %s: %s\n", key, errResp.Message)
+		}
+	})
+}
+
+// Отпечаток запроса: метод, путь и хеш всего, что влияет на результат - заголовков If-Match
+// и X-Actor-ID и тела (запрос с тем же ключом, но другой версией или инициатором считается другим)
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	for _, header := range []string{"If-Match", helpers.ActorHeader} {
+		io.WriteString(hash, header+": "+r.Header.Get(header)+"\n")
+	}
+	hash.Write(body)
+
+	return r.Method + " " + r.URL.Path + " " + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Копия ответа обработчика для сохранения по ключу
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/api/helpers"
+)
+
+func TestFingerprint(t *testing.T) {
+	type request struct {
+		method  string
+		path    string
+		ifMatch string
+		actor   string
+		body    string
+	}
+
+	base := request{"POST", "/pullRequest/merge", `"3"`, "u1", `{"pull_request_id": "pr-1"}`}
+
+	fingerprintOf := func(req request) string {
+		r := httptest.NewRequest(req.method, req.path, nil)
+		if req.ifMatch != "" {
+			r.Header.Set("If-Match", req.ifMatch)
+		}
+		if req.actor != "" {
+			r.Header.Set(helpers.ActorHeader, req.actor)
+		}
+		return fingerprint(r, []byte(req.body))
+	}
+
+	tests := []struct {
+		name     string
+		change   func(req *request)
+		wantSame bool
+	}{
+		{"same request", func(req *request) {}, true},
+		{"other method", func(req *request) { req.method = "PUT" }, false},
+		{"other path", func(req *request) { req.path = "/pullRequest/close" }, false},
+		{"other If-Match", func(req *request) { req.ifMatch = `"4"` }, false},
+		{"no If-Match", func(req *request) { req.ifMatch = "" }, false},
+		{"other actor", func(req *request) { req.actor = "u2" }, false},
+		{"no actor", func(req *request) { req.actor = "" }, false},
+		{"other body", func(req *request) { req.body = `{"pull_request_id": "pr-2"}` }, false},
+		// Заголовок не должен "перетекать" в тело: граница между ними фиксирована
+		{"header moved into body", func(req *request) { req.actor, req.body = "", "u1\n"+req.body }, false},
+	}
+
+	want := fingerprintOf(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			tt.change(&req)
+
+			if got := fingerprintOf(req); (got == want) != tt.wantSame {
+				t.Errorf("fingerprint(%+v) = %q, base %q, want same = %v", req, got, want, tt.wantSame)
+			}
+		})
+	}
+}
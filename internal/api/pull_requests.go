@@ -8,6 +8,7 @@ import (
 	"github.com/tousart/avitotest/internal/api/helpers"
 	"github.com/tousart/avitotest/internal/api/types"
 	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
 	"github.com/tousart/avitotest/internal/usecase"
 )
 
@@ -35,10 +36,67 @@ func (pr *PullRequests) pullRequestCreateHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	helpers.SetETag(w, pullRequest.Version)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(pullRequest)
 }
 
+func (pr *PullRequests) pullRequestGetHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, err := types.CreatePullRequestGetRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestGet(r.Context(), pullRequest)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestListHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := types.CreatePullRequestListRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	var list models.PullRequestList
+	errResp := pr.pullRequestsService.PullRequestList(r.Context(), filter, &list)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
+func (pr *PullRequests) pullRequestHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	history, err := types.CreatePullRequestHistoryRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestHistory(r.Context(), history)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
 func (pr *PullRequests) pullRequestMergeHandler(w http.ResponseWriter, r *http.Request) {
 	pullRequest, err := types.CreatePullRequestMergeRequest(r)
 	if err != nil {
@@ -53,24 +111,140 @@ func (pr *PullRequests) pullRequestMergeHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestReadyHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, err := types.CreatePullRequestStatusRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestReady(r.Context(), pullRequest)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestCloseHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, err := types.CreatePullRequestStatusRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestClose(r.Context(), pullRequest)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestReopenHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, err := types.CreatePullRequestStatusRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestReopen(r.Context(), pullRequest)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestAddReviewerHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, userID, err := types.CreatePullRequestReviewerRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestAddReviewer(r.Context(), pullRequest, userID)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestRemoveReviewerHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, userID, err := types.CreatePullRequestReviewerRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestRemoveReviewer(r.Context(), pullRequest, userID)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pullRequest)
+}
+
+func (pr *PullRequests) pullRequestReviewHandler(w http.ResponseWriter, r *http.Request) {
+	pullRequest, review, err := types.CreatePullRequestReviewRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := pr.pullRequestsService.PullRequestReview(r.Context(), pullRequest, review)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	helpers.SetETag(w, pullRequest.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(pullRequest)
 }
 
 func (pr *PullRequests) pullRequestReassignHandler(w http.ResponseWriter, r *http.Request) {
-	pullRequest, oldUserID, err := types.CreatePullRequestReassign(r)
+	pullRequest, oldUserID, newUserID, err := types.CreatePullRequestReassign(r)
 	if err != nil {
 		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
 		return
 	}
 
-	errResp := pr.pullRequestsService.PullRequestReassign(r.Context(), pullRequest, oldUserID)
+	errResp := pr.pullRequestsService.PullRequestReassign(r.Context(), pullRequest, oldUserID, newUserID)
 	if errResp != nil {
 		status := helpers.GetStatusError(errResp.Code)
 		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
 		return
 	}
 
+	helpers.SetETag(w, pullRequest.Version)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(pullRequest)
 }
@@ -78,7 +252,16 @@ func (pr *PullRequests) pullRequestReassignHandler(w http.ResponseWriter, r *htt
 func (pr *PullRequests) WithPullRequestsHandlers(r chi.Router) {
 	r.Route("/pullRequest", func(r chi.Router) {
 		r.Post("/create", pr.pullRequestCreateHandler)
+		r.Get("/get", pr.pullRequestGetHandler)
+		r.Get("/list", pr.pullRequestListHandler)
+		r.Get("/history", pr.pullRequestHistoryHandler)
 		r.Post("/merge", pr.pullRequestMergeHandler)
+		r.Post("/ready", pr.pullRequestReadyHandler)
+		r.Post("/close", pr.pullRequestCloseHandler)
+		r.Post("/reopen", pr.pullRequestReopenHandler)
 		r.Post("/reassign", pr.pullRequestReassignHandler)
+		r.Post("/review", pr.pullRequestReviewHandler)
+		r.Post("/addReviewer", pr.pullRequestAddReviewerHandler)
+		r.Post("/removeReviewer", pr.pullRequestRemoveReviewerHandler)
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/tousart/avitotest/internal/api/helpers"
 	"github.com/tousart/avitotest/internal/api/types"
 	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
 	"github.com/tousart/avitotest/internal/usecase"
 )
 
@@ -57,9 +58,106 @@ func (t *Teams) teamGetHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(team)
 }
 
+func (t *Teams) teamSetSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	settings, err := types.CreateTeamSetSettingsRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	errResp := t.teamsService.TeamSetSettings(r.Context(), settings)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(settings)
+}
+
+func (t *Teams) teamDeactivateHandler(w http.ResponseWriter, r *http.Request) {
+	deactivation, err := types.CreateTeamDeactivateRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	errResp := t.teamsService.TeamDeactivate(r.Context(), deactivation)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deactivation)
+}
+
+func (t *Teams) teamArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	archive, err := types.CreateTeamArchiveRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	errResp := t.teamsService.TeamArchive(r.Context(), archive)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(archive)
+}
+
+func (t *Teams) teamUnarchiveHandler(w http.ResponseWriter, r *http.Request) {
+	team, err := types.CreateTeamUnarchiveRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	errResp := t.teamsService.TeamUnarchive(r.Context(), team)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(team)
+}
+
+func (t *Teams) teamUpdateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	update, err := types.CreateTeamUpdateMemberRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, err.Error())
+		return
+	}
+
+	var team models.Team
+
+	errResp := t.teamsService.TeamUpdateMember(r.Context(), update, &team)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(team)
+}
+
 func (t *Teams) WithTeamsHandlers(r chi.Router) {
 	r.Route("/team", func(r chi.Router) {
 		r.Post("/add", t.teamAddHandler)
 		r.Get("/get", t.teamGetHandler)
+		r.Post("/setSettings", t.teamSetSettingsHandler)
+		r.Post("/deactivate", t.teamDeactivateHandler)
+		r.Post("/archive", t.teamArchiveHandler)
+		r.Post("/unarchive", t.teamUnarchiveHandler)
+		r.Post("/updateMember", t.teamUpdateMemberHandler)
 	})
 }
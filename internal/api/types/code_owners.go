@@ -0,0 +1,37 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func CreateCodeOwnersSetRequest(r *http.Request) (*models.CodeOwners, error) {
+	var request models.CodeOwners
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.Rules == nil {
+		request.Rules = make([]models.CodeOwnerRule, 0)
+	}
+
+	for _, rule := range request.Rules {
+		if rule.Pattern == "" {
+			return nil, errors.New("rule pattern is required")
+		}
+
+		if (rule.TeamName == "") == (rule.UserID == "") {
+			return nil, errors.New("rule must have either team name or user id")
+		}
+
+		if rule.RequireLead && rule.TeamName == "" {
+			return nil, errors.New("require lead is allowed only for team rules")
+		}
+	}
+
+	return &request, nil
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateCodeOwnersSetRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantRules int
+		wantErr   bool
+	}{
+		{"no rules", `{}`, 0, false},
+		{"empty rules", `{"rules": []}`, 0, false},
+		{"team rule", `{"rules": [{"pattern": "*.sql", "team_name": "backend"}]}`, 1, false},
+		{"user rule", `{"rules": [{"pattern": "/cmd/", "user_id": "u1"}]}`, 1, false},
+		{"lead rule", `{"rules": [{"pattern": "migrations/", "team_name": "backend", "require_lead": true}]}`, 1, false},
+		{"several rules", `{"rules": [{"pattern": "*.go", "team_name": "backend"}, {"pattern": "*.go", "user_id": "u1"}]}`, 2, false},
+		{"no pattern", `{"rules": [{"team_name": "backend"}]}`, 0, true},
+		{"no owner", `{"rules": [{"pattern": "*.sql"}]}`, 0, true},
+		{"team and user", `{"rules": [{"pattern": "*.sql", "team_name": "backend", "user_id": "u1"}]}`, 0, true},
+		{"lead for user", `{"rules": [{"pattern": "*.sql", "user_id": "u1", "require_lead": true}]}`, 0, true},
+		{"bad json", `{"rules": `, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/codeOwners/set", strings.NewReader(tt.body))
+
+			codeOwners, err := CreateCodeOwnersSetRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateCodeOwnersSetRequest(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateCodeOwnersSetRequest(%s) error = %v", tt.body, err)
+			}
+
+			// Правила не бывают nil: пустой список означает удаление всех правил
+			if codeOwners.Rules == nil || len(codeOwners.Rules) != tt.wantRules {
+				t.Errorf("CreateCodeOwnersSetRequest(%s).Rules = %+v, want %d rules", tt.body, codeOwners.Rules, tt.wantRules)
+			}
+		})
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/tousart/avitotest/internal/models"
 )
@@ -27,6 +31,110 @@ func CreatePullRequestCreateRequest(r *http.Request) (*models.PullRequest, error
 		return nil, errors.New("author id is required")
 	}
 
+	if slices.Contains(request.ChangedFiles, "") {
+		return nil, errors.New("changed file path must not be empty")
+	}
+
+	tags, err := NormalizeTags(request.RequiredTags)
+	if err != nil {
+		return nil, err
+	}
+	request.RequiredTags = tags
+
+	return &request, nil
+}
+
+func CreatePullRequestGetRequest(r *http.Request) (*models.PullRequest, error) {
+	var request models.PullRequest
+	request.PullRequestID = r.URL.Query().Get("pull_request_id")
+
+	if request.PullRequestID == "" {
+		return nil, errors.New("pull request id is required")
+	}
+
+	return &request, nil
+}
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+func CreatePullRequestListRequest(r *http.Request) (*models.PullRequestFilter, error) {
+	query := r.URL.Query()
+
+	filter := models.PullRequestFilter{
+		Status:     query.Get("status"),
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		AuthorTeam: query.Get("team_name"),
+		Order:      query.Get("order"),
+		Limit:      DefaultListLimit,
+	}
+
+	if filter.Status != "" && !slices.Contains([]string{models.StatusDraft, models.StatusOpen, models.StatusMerged, models.StatusClosed}, filter.Status) {
+		return nil, errors.New("unknown status")
+	}
+
+	switch filter.Order {
+	case "":
+		filter.Order = "desc"
+	case "asc", "desc":
+	default:
+		return nil, errors.New("order must be asc or desc")
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > MaxListLimit {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		filter.Limit = parsed
+	}
+
+	// Границы периодов: from включительно, to не включительно
+
+	bounds := []struct {
+		name  string
+		value **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+	for _, bound := range bounds {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New(bound.name + " must be in RFC3339 format")
+		}
+		*bound.value = &parsed
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := models.DecodePullRequestCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	return &filter, nil
+}
+
+func CreatePullRequestHistoryRequest(r *http.Request) (*models.PullRequestHistory, error) {
+	var request models.PullRequestHistory
+	request.PullRequestID = r.URL.Query().Get("pull_request_id")
+
+	if request.PullRequestID == "" {
+		return nil, errors.New("pull request id is required")
+	}
+
 	return &request, nil
 }
 
@@ -41,12 +149,103 @@ func CreatePullRequestMergeRequest(r *http.Request) (*models.PullRequest, error)
 		return nil, errors.New("pull request id is required")
 	}
 
-	return &request, nil
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Статус из тела запроса не учитывается: идемпотентность определяется сохраненным статусом
+
+	return &models.PullRequest{
+		PullRequestID:   request.PullRequestID,
+		ExpectedVersion: expectedVersion,
+	}, nil
+}
+
+// Запрос смены статуса пулл реквеста (/pullRequest/ready, /pullRequest/close, /pullRequest/reopen)
+func CreatePullRequestStatusRequest(r *http.Request) (*models.PullRequest, error) {
+	var request models.PullRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.PullRequestID == "" {
+		return nil, errors.New("pull request id is required")
+	}
+
+	return &models.PullRequest{
+		PullRequestID: request.PullRequestID,
+	}, nil
 }
 
-func CreatePullRequestReassign(r *http.Request) (*models.PullRequest, string, error) {
+func CreatePullRequestReassign(r *http.Request) (*models.PullRequest, string, string, error) {
 	var request ReassignRequest
 
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, "", "", err
+	}
+
+	if request.PullRequestID == "" {
+		return nil, "", "", errors.New("pull request id is required")
+	}
+
+	if request.OldUserID == "" {
+		return nil, "", "", errors.New("old user id is required")
+	}
+
+	if request.NewUserID == request.OldUserID {
+		return nil, "", "", errors.New("new user id must differ from old user id")
+	}
+
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return &models.PullRequest{
+		PullRequestID:   request.PullRequestID,
+		ExpectedVersion: expectedVersion,
+	}, request.OldUserID, request.NewUserID, nil
+}
+
+func CreatePullRequestReviewRequest(r *http.Request) (*models.PullRequest, *models.Review, error) {
+	var request ReviewRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, nil, err
+	}
+
+	if request.PullRequestID == "" {
+		return nil, nil, errors.New("pull request id is required")
+	}
+
+	if request.UserID == "" {
+		return nil, nil, errors.New("user id is required")
+	}
+
+	if !slices.Contains([]string{"APPROVED", "CHANGES_REQUESTED"}, request.Verdict) {
+		return nil, nil, errors.New("verdict must be APPROVED or CHANGES_REQUESTED")
+	}
+
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.PullRequest{
+		PullRequestID:   request.PullRequestID,
+		ExpectedVersion: expectedVersion,
+	}, &models.Review{
+		UserID:  request.UserID,
+		Verdict: request.Verdict,
+	}, nil
+}
+
+// Запрос ручного добавления или снятия ревьюера (/pullRequest/addReviewer, /pullRequest/removeReviewer)
+func CreatePullRequestReviewerRequest(r *http.Request) (*models.PullRequest, string, error) {
+	var request ReviewerRequest
+
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		return nil, "", err
 	}
@@ -55,16 +254,57 @@ func CreatePullRequestReassign(r *http.Request) (*models.PullRequest, string, er
 		return nil, "", errors.New("pull request id is required")
 	}
 
-	if request.OldUserID == "" {
-		return nil, "", errors.New("old user id is required")
+	if request.UserID == "" {
+		return nil, "", errors.New("user id is required")
+	}
+
+	expectedVersion, err := ParseIfMatch(r)
+	if err != nil {
+		return nil, "", err
 	}
 
 	return &models.PullRequest{
-		PullRequestID: request.PullRequestID,
-	}, request.OldUserID, nil
+		PullRequestID:   request.PullRequestID,
+		ExpectedVersion: expectedVersion,
+	}, request.UserID, nil
+}
+
+// Ожидаемая версия пулл реквеста из заголовка If-Match (nil - заголовок не передан или "*";
+// для слабого валидатора W/"..." - models.WeakExpectedVersion, операция завершится VERSION_MISMATCH)
+func ParseIfMatch(r *http.Request) (*int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, "W/") {
+		version := models.WeakExpectedVersion
+		return &version, nil
+	}
+
+	value = strings.Trim(value, `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return nil, errors.New("if-match must contain pull request version")
+	}
+
+	return &version, nil
+}
+
+type ReviewerRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+}
+
+type ReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	UserID        string `json:"user_id"`
+	Verdict       string `json:"verdict"`
 }
 
+// new_user_id необязателен: если он не передан, замена выбирается стратегией
 type ReassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id"`
 }
@@ -0,0 +1,222 @@
+package types
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	version := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		want    *int // nil - версия не проверяется
+		wantErr bool
+	}{
+		{"no header", "", nil, false},
+		{"any version", "*", nil, false},
+		{"plain", "3", version(3), false},
+		{"quoted", `"3"`, version(3), false},
+		{"spaces", `  "7"  `, version(7), false},
+		{"weak never matches", `W/"12"`, version(models.WeakExpectedVersion), false},
+		{"zero", "0", nil, true},
+		{"negative", "-1", nil, true},
+		{"not a number", `"abc"`, nil, true},
+		{"list", `"1", "2"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/pullRequest/merge", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ParseIfMatch(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseIfMatch(%q) error = nil, want error", tt.header)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIfMatch(%q) error = %v", tt.header, err)
+			}
+
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseIfMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestReviewerRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		ifMatch string
+		wantErr bool
+	}{
+		{"valid", `{"pull_request_id": "pr-1", "user_id": "u1"}`, "", false},
+		{"with version", `{"pull_request_id": "pr-1", "user_id": "u1"}`, `"2"`, false},
+		{"no pull request", `{"user_id": "u1"}`, "", true},
+		{"no user", `{"pull_request_id": "pr-1"}`, "", true},
+		{"bad version", `{"pull_request_id": "pr-1", "user_id": "u1"}`, "abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/pullRequest/addReviewer", strings.NewReader(tt.body))
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			pullRequest, userID, err := CreatePullRequestReviewerRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreatePullRequestReviewerRequest(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePullRequestReviewerRequest(%s) error = %v", tt.body, err)
+			}
+			if pullRequest.PullRequestID != "pr-1" || userID != "u1" {
+				t.Errorf("CreatePullRequestReviewerRequest(%s) = %q, %q", tt.body, pullRequest.PullRequestID, userID)
+			}
+			if (tt.ifMatch == "") != (pullRequest.ExpectedVersion == nil) {
+				t.Errorf("CreatePullRequestReviewerRequest(%s).ExpectedVersion = %v, If-Match %q", tt.body, pullRequest.ExpectedVersion, tt.ifMatch)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestReassign(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantNewUserID string
+		wantErr       bool
+	}{
+		{"random replacement", `{"pull_request_id": "pr-1", "old_user_id": "u1"}`, "", false},
+		{"chosen replacement", `{"pull_request_id": "pr-1", "old_user_id": "u1", "new_user_id": "u2"}`, "u2", false},
+		{"same user", `{"pull_request_id": "pr-1", "old_user_id": "u1", "new_user_id": "u1"}`, "", true},
+		{"no old user", `{"pull_request_id": "pr-1", "new_user_id": "u2"}`, "", true},
+		{"no pull request", `{"old_user_id": "u1"}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/pullRequest/reassign", strings.NewReader(tt.body))
+
+			_, oldUserID, newUserID, err := CreatePullRequestReassign(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreatePullRequestReassign(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePullRequestReassign(%s) error = %v", tt.body, err)
+			}
+			if oldUserID != "u1" || newUserID != tt.wantNewUserID {
+				t.Errorf("CreatePullRequestReassign(%s) = %q, %q, want %q, %q", tt.body, oldUserID, newUserID, "u1", tt.wantNewUserID)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestGetRequest(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"pull_request_id=pr-1", false},
+		{"pull_request_id=", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/pullRequest/get?"+tt.query, nil)
+
+			pullRequest, err := CreatePullRequestGetRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreatePullRequestGetRequest(%q) error = nil, want error", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePullRequestGetRequest(%q) error = %v", tt.query, err)
+			}
+			if pullRequest.PullRequestID != "pr-1" {
+				t.Errorf("CreatePullRequestGetRequest(%q).PullRequestID = %q", tt.query, pullRequest.PullRequestID)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestStatusRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"valid", `{"pull_request_id": "pr-1"}`, false},
+		{"no pull request", `{}`, true},
+		{"bad json", `{"pull_request_id": `, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/pullRequest/reopen", strings.NewReader(tt.body))
+
+			_, err := CreatePullRequestStatusRequest(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreatePullRequestStatusRequest(%s) error = %v, want error = %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequestReviewRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"approved", `{"pull_request_id": "pr-1", "user_id": "u1", "verdict": "APPROVED"}`, false},
+		{"changes requested", `{"pull_request_id": "pr-1", "user_id": "u1", "verdict": "CHANGES_REQUESTED"}`, false},
+		{"lowercase verdict", `{"pull_request_id": "pr-1", "user_id": "u1", "verdict": "approved"}`, true},
+		{"no verdict", `{"pull_request_id": "pr-1", "user_id": "u1"}`, true},
+		{"no user", `{"pull_request_id": "pr-1", "verdict": "APPROVED"}`, true},
+		{"no pull request", `{"user_id": "u1", "verdict": "APPROVED"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/pullRequest/review", strings.NewReader(tt.body))
+
+			_, review, err := CreatePullRequestReviewRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreatePullRequestReviewRequest(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePullRequestReviewRequest(%s) error = %v", tt.body, err)
+			}
+			if review.UserID != "u1" || review.Verdict == "" {
+				t.Errorf("CreatePullRequestReviewRequest(%s) review = %+v", tt.body, review)
+			}
+		})
+	}
+}
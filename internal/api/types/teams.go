@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"net/http"
+	"slices"
 
 	"errors"
 
@@ -24,6 +25,32 @@ func CreateTeamAddRequest(r *http.Request) (*models.Team, error) {
 		return nil, errors.New("members are required")
 	}
 
+	if request.ReviewersCount < 0 {
+		return nil, errors.New("reviewers count must be positive")
+	}
+
+	if request.MaxOpenReviews < 0 {
+		return nil, errors.New("max open reviews must not be negative")
+	}
+
+	if request.RequiredApprovals != nil && *request.RequiredApprovals < 0 {
+		return nil, errors.New("required approvals must not be negative")
+	}
+
+	if request.MemberConflict != "" && !slices.Contains([]string{models.MemberConflictMove, models.MemberConflictJoin, models.MemberConflictReject}, request.MemberConflict) {
+		return nil, errors.New("member conflict must be move, join or reject")
+	}
+
+	for _, member := range request.Members {
+		if member.Role != "" && !validRole(member.Role) {
+			return nil, errors.New("role must be lead, member or observer")
+		}
+	}
+
+	if err := validateFallbackTeams(request.TeamName, request.FallbackTeams); err != nil {
+		return nil, err
+	}
+
 	return &request, nil
 }
 
@@ -37,3 +64,137 @@ func CreateTeamGetRequest(r *http.Request) (*models.Team, error) {
 
 	return &request, nil
 }
+
+func CreateTeamSetSettingsRequest(r *http.Request) (*models.TeamSettings, error) {
+	var request models.TeamSettings
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team name is required")
+	}
+
+	if request.ReviewersCount < 0 {
+		return nil, errors.New("reviewers count must be positive")
+	}
+
+	if request.MaxOpenReviews != nil && *request.MaxOpenReviews < 0 {
+		return nil, errors.New("max open reviews must not be negative")
+	}
+
+	if request.RequiredApprovals != nil && *request.RequiredApprovals < 0 {
+		return nil, errors.New("required approvals must not be negative")
+	}
+
+	if request.ReviewersCount == 0 && request.MaxOpenReviews == nil && request.RequiredApprovals == nil && request.FallbackTeams == nil {
+		return nil, errors.New("reviewers count, max open reviews, required approvals or fallback teams are required")
+	}
+
+	if err := validateFallbackTeams(request.TeamName, request.FallbackTeams); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func CreateTeamDeactivateRequest(r *http.Request) (*models.TeamDeactivation, error) {
+	var request models.TeamDeactivation
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team name is required")
+	}
+
+	request.UserIDs = slices.Compact(slices.Sorted(slices.Values(request.UserIDs)))
+	if slices.Contains(request.UserIDs, "") {
+		return nil, errors.New("user ids must not be empty")
+	}
+
+	return &request, nil
+}
+
+func CreateTeamArchiveRequest(r *http.Request) (*models.TeamArchive, error) {
+	var request models.TeamArchive
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team name is required")
+	}
+
+	if request.MoveToTeam == request.TeamName {
+		return nil, errors.New("members can not be moved to the archived team")
+	}
+
+	return &models.TeamArchive{
+		TeamName:   request.TeamName,
+		MoveToTeam: request.MoveToTeam,
+	}, nil
+}
+
+func CreateTeamUnarchiveRequest(r *http.Request) (*models.Team, error) {
+	var request models.Team
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team name is required")
+	}
+
+	return &models.Team{
+		TeamName: request.TeamName,
+	}, nil
+}
+
+func CreateTeamUpdateMemberRequest(r *http.Request) (*models.TeamMemberUpdate, error) {
+	var request models.TeamMemberUpdate
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team name is required")
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user id is required")
+	}
+
+	if !validRole(request.Role) {
+		return nil, errors.New("role must be lead, member or observer")
+	}
+
+	return &request, nil
+}
+
+func validRole(role string) bool {
+	return slices.Contains([]string{models.RoleLead, models.RoleMember, models.RoleObserver}, role)
+}
+
+func validateFallbackTeams(teamName string, fallbackTeams []string) error {
+	for i, fallbackTeam := range fallbackTeams {
+		if fallbackTeam == "" {
+			return errors.New("fallback team name is required")
+		}
+
+		if fallbackTeam == teamName {
+			return errors.New("team can not be its own fallback team")
+		}
+
+		if slices.Contains(fallbackTeams[:i], fallbackTeam) {
+			return errors.New("fallback teams must be unique")
+		}
+	}
+
+	return nil
+}
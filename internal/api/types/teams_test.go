@@ -0,0 +1,221 @@
+package types
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateFallbackTeams(t *testing.T) {
+	tests := []struct {
+		name          string
+		fallbackTeams []string
+		wantErr       bool
+	}{
+		{"no fallback teams", nil, false},
+		{"empty list", []string{}, false},
+		{"one", []string{"frontend"}, false},
+		{"several", []string{"frontend", "mobile"}, false},
+		{"empty name", []string{"frontend", ""}, true},
+		{"own team", []string{"backend"}, true},
+		{"duplicate", []string{"frontend", "mobile", "frontend"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFallbackTeams("backend", tt.fallbackTeams)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFallbackTeams(%q, %q) error = %v, want error = %v", "backend", tt.fallbackTeams, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateTeamAddRequestMemberConflict(t *testing.T) {
+	tests := []struct {
+		memberConflict string
+		wantErr        bool
+	}{
+		{"", false},
+		{"move", false},
+		{"join", false},
+		{"reject", false},
+		{"MOVE", true},
+		{"merge", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.memberConflict, func(t *testing.T) {
+			body := `{"team_name": "backend", "member_conflict": "` + tt.memberConflict + `", "members": [{"user_id": "u1", "username": "Alice", "is_active": true}]}`
+			r := httptest.NewRequest("POST", "/team/add", strings.NewReader(body))
+
+			team, err := CreateTeamAddRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateTeamAddRequest(%q) error = nil, want error", tt.memberConflict)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTeamAddRequest(%q) error = %v", tt.memberConflict, err)
+			}
+			if team.MemberConflict != tt.memberConflict {
+				t.Errorf("CreateTeamAddRequest(%q).MemberConflict = %q", tt.memberConflict, team.MemberConflict)
+			}
+		})
+	}
+}
+
+func TestCreateTeamAddRequestRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		wantErr bool
+	}{
+		{"no role", []string{""}, false},
+		{"all roles", []string{"lead", "member", "observer"}, false},
+		{"several leads", []string{"lead", "lead"}, false},
+		{"unknown role", []string{"member", "owner"}, true},
+		{"wrong case", []string{"Lead"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := make([]string, 0, len(tt.roles))
+			for i, role := range tt.roles {
+				members = append(members, fmt.Sprintf(`{"user_id": "u%d", "username": "User %d", "is_active": true, "role": %q}`, i, i, role))
+			}
+			body := `{"team_name": "backend", "members": [` + strings.Join(members, ", ") + `]}`
+			r := httptest.NewRequest("POST", "/team/add", strings.NewReader(body))
+
+			team, err := CreateTeamAddRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateTeamAddRequest(%q) error = nil, want error", tt.roles)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTeamAddRequest(%q) error = %v", tt.roles, err)
+			}
+			for i, member := range team.Members {
+				if member.Role != tt.roles[i] {
+					t.Errorf("CreateTeamAddRequest(%q).Members[%d].Role = %q", tt.roles, i, member.Role)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTeamUpdateMemberRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"lead", `{"team_name": "backend", "user_id": "u1", "role": "lead"}`, false},
+		{"observer", `{"team_name": "backend", "user_id": "u1", "role": "observer"}`, false},
+		{"no role", `{"team_name": "backend", "user_id": "u1"}`, true},
+		{"unknown role", `{"team_name": "backend", "user_id": "u1", "role": "owner"}`, true},
+		{"no team", `{"user_id": "u1", "role": "member"}`, true},
+		{"no user", `{"team_name": "backend", "role": "member"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/team/updateMember", strings.NewReader(tt.body))
+
+			_, err := CreateTeamUpdateMemberRequest(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTeamUpdateMemberRequest(%s) error = %v, want error = %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateTeamSetSettingsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"reviewers count", `{"team_name": "backend", "reviewers_count": 3}`, false},
+		{"one reviewer", `{"team_name": "backend", "reviewers_count": 1}`, false},
+		{"only other settings", `{"team_name": "backend", "max_open_reviews": 5}`, false},
+		{"negative reviewers count", `{"team_name": "backend", "reviewers_count": -1}`, true},
+		{"nothing to change", `{"team_name": "backend"}`, true},
+		{"no team", `{"reviewers_count": 3}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/team/setSettings", strings.NewReader(tt.body))
+
+			_, err := CreateTeamSetSettingsRequest(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTeamSetSettingsRequest(%s) error = %v, want error = %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateTeamDeactivateRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantUserIDs []string
+		wantErr     bool
+	}{
+		{"whole team", `{"team_name": "backend"}`, []string{}, false},
+		{"sorted", `{"team_name": "backend", "user_ids": ["u2", "u1"]}`, []string{"u1", "u2"}, false},
+		{"duplicates removed", `{"team_name": "backend", "user_ids": ["u1", "u2", "u1"]}`, []string{"u1", "u2"}, false},
+		{"empty user id", `{"team_name": "backend", "user_ids": ["u1", ""]}`, nil, true},
+		{"no team", `{"user_ids": ["u1"]}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/team/deactivate", strings.NewReader(tt.body))
+
+			deactivation, err := CreateTeamDeactivateRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateTeamDeactivateRequest(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTeamDeactivateRequest(%s) error = %v", tt.body, err)
+			}
+			if !slices.Equal(deactivation.UserIDs, tt.wantUserIDs) {
+				t.Errorf("CreateTeamDeactivateRequest(%s).UserIDs = %q, want %q", tt.body, deactivation.UserIDs, tt.wantUserIDs)
+			}
+		})
+	}
+}
+
+func TestCreateTeamArchiveRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"archive", `{"team_name": "backend"}`, false},
+		{"move members", `{"team_name": "backend", "move_to_team": "platform"}`, false},
+		{"move to itself", `{"team_name": "backend", "move_to_team": "backend"}`, true},
+		{"no team", `{"move_to_team": "platform"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/team/archive", strings.NewReader(tt.body))
+
+			_, err := CreateTeamArchiveRequest(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTeamArchiveRequest(%s) error = %v, want error = %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
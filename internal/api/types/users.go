@@ -3,11 +3,154 @@ package types
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/tousart/avitotest/internal/models"
 )
 
+func CreateGetUserRequest(r *http.Request) (*models.User, error) {
+	var request models.User
+	request.UserID = r.URL.Query().Get("user_id")
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	return &request, nil
+}
+
+func CreateCreateUserRequest(r *http.Request) (*models.User, error) {
+	var request CreateUserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	if request.Username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team_name is required")
+	}
+
+	maxOpenReviews, _, err := parseMaxOpenReviews(request.MaxOpenReviews)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := NormalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// По умолчанию пользователь создается активным
+
+	isActive := true
+	if request.IsActive != nil {
+		isActive = *request.IsActive
+	}
+
+	return &models.User{
+		UserID:         request.UserID,
+		Username:       request.Username,
+		TeamName:       request.TeamName,
+		IsActive:       isActive,
+		Tags:           tags,
+		MaxOpenReviews: maxOpenReviews,
+	}, nil
+}
+
+func CreateUpdateUserRequest(r *http.Request) (*models.UserUpdate, error) {
+	var request UpdateUserRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	maxOpenReviews, maxOpenReviewsSet, err := parseMaxOpenReviews(request.MaxOpenReviews)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Username == nil && request.Tags == nil && !maxOpenReviewsSet {
+		return nil, errors.New("username, tags or max_open_reviews are required")
+	}
+
+	if request.Username != nil && *request.Username == "" {
+		return nil, errors.New("username must not be empty")
+	}
+
+	// max_open_reviews: null сбрасывает собственный лимит (берется лимит команды)
+
+	update := models.UserUpdate{
+		UserID:                request.UserID,
+		Username:              request.Username,
+		MaxOpenReviews:        maxOpenReviews,
+		InheritMaxOpenReviews: maxOpenReviewsSet && maxOpenReviews == nil,
+	}
+
+	// Пустой список тегов удаляет все теги, отсутствующий - оставляет без изменений
+
+	if request.Tags != nil {
+		tags, err := NormalizeTags(request.Tags)
+		if err != nil {
+			return nil, err
+		}
+		update.Tags = tags
+	}
+
+	return &update, nil
+}
+
+func CreateListUsersRequest(r *http.Request) (*models.UserFilter, error) {
+	query := r.URL.Query()
+
+	filter := models.UserFilter{
+		TeamName: query.Get("team_name"),
+		Limit:    DefaultListLimit,
+	}
+
+	if isActive := query.Get("is_active"); isActive != "" {
+		parsed, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return nil, errors.New("is_active must be true or false")
+		}
+		filter.IsActive = &parsed
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > MaxListLimit {
+			return nil, errors.New("limit must be between 1 and 100")
+		}
+		filter.Limit = parsed
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := models.DecodeUserCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	return &filter, nil
+}
+
 func CreateSetIsActiveRequest(r *http.Request) (*models.User, error) {
 	var request *models.User
 
@@ -22,6 +165,102 @@ func CreateSetIsActiveRequest(r *http.Request) (*models.User, error) {
 	return request, nil
 }
 
+// /users/setTags - частный случай /users/update: теги заменяются целиком
+func CreateSetTagsRequest(r *http.Request) (*models.UserUpdate, error) {
+	var request SetTagsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	tags, err := NormalizeTags(request.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserUpdate{
+		UserID: request.UserID,
+		Tags:   tags,
+	}, nil
+}
+
+// /users/setMaxOpenReviews - частный случай /users/update
+// (max_open_reviews: null - брать лимит команды, 0 - пользователь не назначается ревьюером автоматически)
+func CreateSetMaxOpenReviewsRequest(r *http.Request) (*models.UserUpdate, error) {
+	var request SetMaxOpenReviewsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	maxOpenReviews, maxOpenReviewsSet, err := parseMaxOpenReviews(request.MaxOpenReviews)
+	if err != nil {
+		return nil, err
+	}
+
+	if !maxOpenReviewsSet {
+		return nil, errors.New("max_open_reviews is required")
+	}
+
+	return &models.UserUpdate{
+		UserID:                request.UserID,
+		MaxOpenReviews:        maxOpenReviews,
+		InheritMaxOpenReviews: maxOpenReviews == nil,
+	}, nil
+}
+
+// Запрос смены команды пользователя (/users/moveTeam, /users/joinTeam, /users/leaveTeam)
+func CreateMoveTeamRequest(r *http.Request) (*models.User, error) {
+	var request models.User
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	if request.TeamName == "" {
+		return nil, errors.New("team_name is required")
+	}
+
+	return &models.User{
+		UserID:   request.UserID,
+		TeamName: request.TeamName,
+	}, nil
+}
+
+func CreateAddAbsenceRequest(r *http.Request) (*models.Absence, error) {
+	var request models.Absence
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+
+	if request.UserID == "" {
+		return nil, errors.New("user_id is required")
+	}
+
+	if request.StartsAt.IsZero() || request.EndsAt.IsZero() {
+		return nil, errors.New("starts_at and ends_at are required")
+	}
+
+	if !request.EndsAt.After(request.StartsAt) {
+		return nil, errors.New("ends_at must be after starts_at")
+	}
+
+	return &request, nil
+}
+
 func CreateGetReview(r *http.Request) (*[]models.PullRequestShort, string, error) {
 	userID := r.URL.Query().Get("user_id")
 
@@ -33,3 +272,72 @@ func CreateGetReview(r *http.Request) (*[]models.PullRequestShort, string, error
 
 	return &pullRequests, userID, nil
 }
+
+// is_active необязателен (по умолчанию true)
+type CreateUserRequest struct {
+	UserID         string          `json:"user_id"`
+	Username       string          `json:"username"`
+	TeamName       string          `json:"team_name"`
+	IsActive       *bool           `json:"is_active"`
+	Tags           []string        `json:"tags"`
+	MaxOpenReviews json.RawMessage `json:"max_open_reviews"`
+}
+
+type UpdateUserRequest struct {
+	UserID         string          `json:"user_id"`
+	Username       *string         `json:"username"`
+	Tags           []string        `json:"tags"`
+	MaxOpenReviews json.RawMessage `json:"max_open_reviews"`
+}
+
+type SetMaxOpenReviewsRequest struct {
+	UserID         string          `json:"user_id"`
+	MaxOpenReviews json.RawMessage `json:"max_open_reviews"`
+}
+
+// Разбор max_open_reviews: вторым значением возвращается, было ли поле передано (null - передано без значения)
+func parseMaxOpenReviews(raw json.RawMessage) (*int, bool, error) {
+	if len(raw) == 0 {
+		return nil, false, nil
+	}
+
+	var maxOpenReviews *int
+	if err := json.Unmarshal(raw, &maxOpenReviews); err != nil {
+		return nil, false, errors.New("max_open_reviews must be an integer or null")
+	}
+
+	if maxOpenReviews != nil && *maxOpenReviews < 0 {
+		return nil, false, errors.New("max_open_reviews must not be negative")
+	}
+
+	return maxOpenReviews, true, nil
+}
+
+type SetTagsRequest struct {
+	UserID string   `json:"user_id"`
+	Tags   []string `json:"tags"`
+}
+
+// Максимальная длина тега (столбцы tag в user_tags и pr_tags - VARCHAR(64))
+const MaxTagLength = 64
+
+// Теги приводятся к нижнему регистру, пустые и слишком длинные теги запрещены, повторы удаляются
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, errors.New("tag must not be empty")
+		}
+
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag must not be longer than %d characters", MaxTagLength)
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized), nil
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateAddAbsenceRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"valid", `{"user_id": "u1", "starts_at": "2025-12-01T00:00:00Z", "ends_at": "2025-12-15T00:00:00Z"}`, false},
+		{"with offset", `{"user_id": "u1", "starts_at": "2025-12-01T09:00:00+03:00", "ends_at": "2025-12-01T07:00:00Z"}`, false},
+		{"ends before start", `{"user_id": "u1", "starts_at": "2025-12-15T00:00:00Z", "ends_at": "2025-12-01T00:00:00Z"}`, true},
+		{"empty period", `{"user_id": "u1", "starts_at": "2025-12-01T00:00:00Z", "ends_at": "2025-12-01T00:00:00Z"}`, true},
+		{"no end", `{"user_id": "u1", "starts_at": "2025-12-01T00:00:00Z"}`, true},
+		{"no user", `{"starts_at": "2025-12-01T00:00:00Z", "ends_at": "2025-12-15T00:00:00Z"}`, true},
+		{"not a time", `{"user_id": "u1", "starts_at": "tomorrow", "ends_at": "2025-12-15T00:00:00Z"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/users/addAbsence", strings.NewReader(tt.body))
+
+			_, err := CreateAddAbsenceRequest(r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateAddAbsenceRequest(%s) error = %v, want error = %v", tt.body, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseMaxOpenReviews(t *testing.T) {
+	limit := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		raw     string
+		want    *int
+		wantSet bool
+		wantErr bool
+	}{
+		{"", nil, false, false},
+		{"null", nil, true, false},
+		{"0", limit(0), true, false},
+		{"5", limit(5), true, false},
+		{"-1", nil, false, true},
+		{"1.5", nil, false, true},
+		{`"5"`, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, set, err := parseMaxOpenReviews(json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseMaxOpenReviews(%s) error = nil, want error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMaxOpenReviews(%s) error = %v", tt.raw, err)
+			}
+
+			if set != tt.wantSet || (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("parseMaxOpenReviews(%s) = %v, %v, want %v, %v", tt.raw, got, set, tt.want, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestCreateMoveTeamRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		wantErr bool
+	}{
+		{"move", "/users/moveTeam", `{"user_id": "u1", "team_name": "backend"}`, false},
+		{"join", "/users/joinTeam", `{"user_id": "u1", "team_name": "frontend"}`, false},
+		{"leave", "/users/leaveTeam", `{"user_id": "u1", "team_name": "frontend"}`, false},
+		{"no team", "/users/joinTeam", `{"user_id": "u1"}`, true},
+		{"no user", "/users/leaveTeam", `{"team_name": "frontend"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+
+			user, err := CreateMoveTeamRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CreateMoveTeamRequest(%s) error = nil, want error", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateMoveTeamRequest(%s) error = %v", tt.body, err)
+			}
+			if user.UserID != "u1" || user.TeamName == "" {
+				t.Errorf("CreateMoveTeamRequest(%s) = %+v", tt.body, user)
+			}
+		})
+	}
+}
@@ -22,6 +22,80 @@ func CreateUsersAPI(usersService usecase.UsersService) *Users {
 	}
 }
 
+func (u *Users) usersGetHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := types.CreateGetUserRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.GetUser(r.Context(), user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersCreateHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := types.CreateCreateUserRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.CreateUser(r.Context(), user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	update, err := types.CreateUpdateUserRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	var user models.User
+	errResp := u.usersService.UpdateUser(r.Context(), update, &user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersListHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := types.CreateListUsersRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	var list models.UserList
+	errResp := u.usersService.ListUsers(r.Context(), filter, &list)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(list)
+}
+
 func (u *Users) usersSetIsActiveHandler(w http.ResponseWriter, r *http.Request) {
 	user, err := types.CreateSetIsActiveRequest(r)
 	if err != nil {
@@ -40,6 +114,116 @@ func (u *Users) usersSetIsActiveHandler(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(user)
 }
 
+func (u *Users) usersSetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	update, err := types.CreateSetTagsRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	var user models.User
+	errResp := u.usersService.UpdateUser(r.Context(), update, &user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersSetMaxOpenReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	update, err := types.CreateSetMaxOpenReviewsRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	var user models.User
+	errResp := u.usersService.UpdateUser(r.Context(), update, &user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersMoveTeamHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := types.CreateMoveTeamRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.MoveTeam(r.Context(), user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersJoinTeamHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := types.CreateMoveTeamRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.JoinTeam(r.Context(), user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersLeaveTeamHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := types.CreateMoveTeamRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.LeaveTeam(r.Context(), user)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (u *Users) usersAddAbsenceHandler(w http.ResponseWriter, r *http.Request) {
+	absence, err := types.CreateAddAbsenceRequest(r)
+	if err != nil {
+		helpers.WriteAPIError(w, http.StatusBadRequest, codes.ErrBadRequet, "bad request")
+		return
+	}
+
+	errResp := u.usersService.AddAbsence(r.Context(), absence)
+	if errResp != nil {
+		status := helpers.GetStatusError(errResp.Code)
+		helpers.WriteAPIError(w, status, errResp.Code, errResp.Message)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(absence)
+}
+
 func (u *Users) usersGetReviewHandler(w http.ResponseWriter, r *http.Request) {
 	pullRequests, userID, err := types.CreateGetReview(r)
 	if err != nil {
@@ -74,7 +258,17 @@ func (u *Users) usersGetActivityHandler(w http.ResponseWriter, r *http.Request)
 
 func (u *Users) WithUsersHandlers(r chi.Router) {
 	r.Route("/users", func(r chi.Router) {
+		r.Get("/get", u.usersGetHandler)
+		r.Post("/create", u.usersCreateHandler)
+		r.Post("/update", u.usersUpdateHandler)
+		r.Get("/list", u.usersListHandler)
 		r.Post("/setIsActive", u.usersSetIsActiveHandler)
+		r.Post("/setTags", u.usersSetTagsHandler)
+		r.Post("/setMaxOpenReviews", u.usersSetMaxOpenReviewsHandler)
+		r.Post("/moveTeam", u.usersMoveTeamHandler)
+		r.Post("/joinTeam", u.usersJoinTeamHandler)
+		r.Post("/leaveTeam", u.usersLeaveTeamHandler)
+		r.Post("/addAbsence", u.usersAddAbsenceHandler)
 		r.Get("/getReview", u.usersGetReviewHandler)
 		r.Get("/getActivity", u.usersGetActivityHandler)
 	})
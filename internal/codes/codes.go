@@ -1,12 +1,24 @@
 package codes
 
 const (
-	ErrTeamExists  = "TEAM_EXISTS"
-	ErrPRExists    = "PR_EXISTS"
-	ErrPRMerged    = "PR_MERGED"
-	ErrNotAssigned = "NOT_ASSIGNED"
-	ErrNoCandidate = "NO_CANDIDATE"
-	ErrNotFound    = "NOT_FOUND"
-	ErrBadRequet   = "BAD_REQUEST"    // Добавил от себя
-	ErrInternal    = "INTERNAL_ERROR" // Добавил от себя
+	ErrTeamExists        = "TEAM_EXISTS"
+	ErrTeamArchived      = "TEAM_ARCHIVED"
+	ErrUserInOtherTeam   = "USER_IN_OTHER_TEAM"
+	ErrUserExists        = "USER_EXISTS"
+	ErrPrimaryTeam       = "PRIMARY_TEAM"
+	ErrPRExists          = "PR_EXISTS"
+	ErrPRMerged          = "PR_MERGED"
+	ErrNotAssigned       = "NOT_ASSIGNED"
+	ErrNoCandidate       = "NO_CANDIDATE"
+	ErrCapacityExceeded  = "CAPACITY_EXCEEDED"
+	ErrNotEligible       = "REVIEWER_NOT_ELIGIBLE"
+	ErrInvalidTransition = "INVALID_STATUS_TRANSITION"
+	ErrMergeBlocked      = "MERGE_BLOCKED"
+	ErrVersionMismatch   = "VERSION_MISMATCH"
+	ErrIdempotencyReused = "IDEMPOTENCY_KEY_REUSED"
+	ErrIdempotencyBusy   = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	ErrForbidden         = "FORBIDDEN"
+	ErrNotFound          = "NOT_FOUND"
+	ErrBadRequet         = "BAD_REQUEST"    // Добавил от себя
+	ErrInternal          = "INTERNAL_ERROR" // Добавил от себя
 )
@@ -0,0 +1,15 @@
+package models
+
+import "context"
+
+type actorKey struct{}
+
+// Инициатор операции (пишется в историю пулл реквестов)
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
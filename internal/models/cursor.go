@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Курсор передается клиенту как непрозрачная строка
+func (c PullRequestCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.PullRequestID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodePullRequestCursor(cursor string) (*PullRequestCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	createdAt, pullRequestID, ok := strings.Cut(string(raw), "|")
+	if !ok || pullRequestID == "" {
+		return nil, errors.New("invalid cursor")
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &PullRequestCursor{
+		CreatedAt:     parsed,
+		PullRequestID: pullRequestID,
+	}, nil
+}
+
+// Курсор списка пользователей - user_id последнего пользователя страницы
+func EncodeUserCursor(userID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(userID))
+}
+
+func DecodeUserCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) == 0 {
+		return "", errors.New("invalid cursor")
+	}
+
+	return string(raw), nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func TestPullRequestCursor(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	cursors := []PullRequestCursor{
+		{CreatedAt: time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC), PullRequestID: "pr-1"},
+		{CreatedAt: time.Date(2025, 11, 20, 10, 0, 0, 123456000, time.UTC), PullRequestID: "pr-1228"},
+		{CreatedAt: time.Date(2025, 11, 20, 13, 0, 0, 0, moscow), PullRequestID: "pr|with|pipes"},
+	}
+
+	for _, cursor := range cursors {
+		t.Run(cursor.PullRequestID, func(t *testing.T) {
+			decoded, err := DecodePullRequestCursor(cursor.Encode())
+			if err != nil {
+				t.Fatalf("DecodePullRequestCursor() error = %v", err)
+			}
+
+			if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.PullRequestID != cursor.PullRequestID {
+				t.Errorf("DecodePullRequestCursor(Encode()) = %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+func TestDecodePullRequestCursorInvalid(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"no separator", encode("2025-11-20T10:00:00Z")},
+		{"no pull request id", encode("2025-11-20T10:00:00Z|")},
+		{"bad time", encode("yesterday|pr-1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := DecodePullRequestCursor(tt.cursor); err == nil {
+				t.Errorf("DecodePullRequestCursor(%q) = %+v, want error", tt.cursor, cursor)
+			}
+		})
+	}
+}
+
+func TestUserCursor(t *testing.T) {
+	for _, userID := range []string{"u1", "user with spaces", "юзер/1"} {
+		t.Run(userID, func(t *testing.T) {
+			decoded, err := DecodeUserCursor(EncodeUserCursor(userID))
+			if err != nil {
+				t.Fatalf("DecodeUserCursor() error = %v", err)
+			}
+			if decoded != userID {
+				t.Errorf("DecodeUserCursor(EncodeUserCursor(%q)) = %q", userID, decoded)
+			}
+		})
+	}
+
+	for _, cursor := range []string{"", "!!!"} {
+		if userID, err := DecodeUserCursor(cursor); err == nil {
+			t.Errorf("DecodeUserCursor(%q) = %q, want error", cursor, userID)
+		}
+	}
+}
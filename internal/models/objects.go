@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // ErrorResponse
 
 type ErrorResponse struct {
@@ -9,24 +11,150 @@ type ErrorResponse struct {
 
 // Team
 
+// primary_team - основная команда участника (в ответе /team/get);
+// role - роль в команде: lead, member (по умолчанию) или observer
 type TeamMember struct {
+	UserID      string `json:"user_id"`
+	Username    string `json:"username"`
+	IsActive    bool   `json:"is_active"`
+	PrimaryTeam string `json:"primary_team,omitempty"`
+	Role        string `json:"role,omitempty"`
+}
+
+const (
+	RoleLead     = "lead"
+	RoleMember   = "member"
+	RoleObserver = "observer"
+)
+
+// Изменение роли участника команды
+type TeamMemberUpdate struct {
+	TeamName string `json:"team_name"`
 	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	Role     string `json:"role"`
 }
 
+// member_conflict - что делать с участниками, которые уже состоят в другой команде:
+// move (по умолчанию) - сделать новую команду основной, join - добавить дополнительными участниками,
+// reject - отклонить создание команды
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName          string              `json:"team_name"`
+	ReviewersCount    int                 `json:"reviewers_count"`
+	MaxOpenReviews    int                 `json:"max_open_reviews"`
+	RequiredApprovals *int                `json:"required_approvals"`
+	FallbackTeams     []string            `json:"fallback_teams"`
+	Members           []TeamMember        `json:"members"`
+	Absences          []Absence           `json:"absences,omitempty"`
+	ArchivedAt        *time.Time          `json:"archived_at,omitempty"`
+	MemberConflict    string              `json:"member_conflict,omitempty"`
+	Reassignment      *ReassignmentReport `json:"reassignment,omitempty"`
+}
+
+const (
+	MemberConflictMove   = "move"
+	MemberConflictJoin   = "join"
+	MemberConflictReject = "reject"
+)
+
+// Настройки команды (fallback_teams - запасные команды в порядке приоритета,
+// из которых берутся ревьюеры, если в команде не хватает кандидатов;
+// max_open_reviews - лимит OPEN ревью участника по умолчанию, 0 - без лимита;
+// required_approvals - сколько одобрений нужно для merge пулл реквестов авторов команды, 0 - merge без одобрений)
+type TeamSettings struct {
+	TeamName          string     `json:"team_name"`
+	ReviewersCount    int        `json:"reviewers_count"`
+	MaxOpenReviews    *int       `json:"max_open_reviews"`
+	RequiredApprovals *int       `json:"required_approvals"`
+	FallbackTeams     []string   `json:"fallback_teams"`
+	ArchivedAt        *time.Time `json:"-"`
+}
+
+// Массовая деактивация участников команды (все участники, если user_ids не переданы)
+type TeamDeactivation struct {
+	TeamName     string              `json:"team_name"`
+	UserIDs      []string            `json:"user_ids,omitempty"`
+	Deactivated  []string            `json:"deactivated"`
+	Reassignment *ReassignmentReport `json:"reassignment"`
+}
+
+// Архивация команды: участники переводятся в move_to_team (если передана) или деактивируются,
+// их OPEN ревью переназначаются. Архивная команда остается для истории, но не участвует в назначении ревьюеров
+type TeamArchive struct {
+	TeamName     string              `json:"team_name"`
+	MoveToTeam   string              `json:"move_to_team,omitempty"`
+	Moved        []string            `json:"moved,omitempty"`
+	Deactivated  []string            `json:"deactivated,omitempty"`
+	Reassignment *ReassignmentReport `json:"reassignment"`
+	ArchivedAt   *time.Time          `json:"archived_at"`
 }
 
 // User
 
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID         string              `json:"user_id"`
+	Username       string              `json:"username"`
+	TeamName       string              `json:"team_name"`
+	IsActive       bool                `json:"is_active"`
+	Tags           []string            `json:"tags,omitempty"`
+	MaxOpenReviews *int                `json:"max_open_reviews,omitempty"`
+	Reassignment   *ReassignmentReport `json:"reassignment,omitempty"`
+	Memberships    []TeamMembership    `json:"memberships,omitempty"`
+}
+
+// Изменение профиля пользователя (nil - поле не меняется; InheritMaxOpenReviews - сбросить собственный лимит
+// и брать значение команды; max_open_reviews 0 - пользователь не назначается ревьюером автоматически)
+type UserUpdate struct {
+	UserID                string
+	Username              *string
+	Tags                  []string
+	MaxOpenReviews        *int
+	InheritMaxOpenReviews bool
+}
+
+// Фильтры и пагинация списка пользователей (пустые поля и nil не фильтруют)
+type UserFilter struct {
+	TeamName string
+	IsActive *bool
+	Limit    int
+	After    string // user_id последнего пользователя предыдущей страницы
+}
+
+type UserList struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Период членства пользователя в команде (left_at не задан - текущая команда)
+type TeamMembership struct {
+	TeamName string     `json:"team_name"`
+	Role     string     `json:"role"`
+	JoinedAt time.Time  `json:"joined_at"`
+	LeftAt   *time.Time `json:"left_at,omitempty"`
+}
+
+// Отчет о переназначении OPEN пулл реквестов при деактивации пользователей
+type ReassignmentReport struct {
+	Reassigned       []ReviewReassignment `json:"reassigned"`
+	NoCandidate      []string             `json:"no_candidate"`
+	CapacityExceeded []string             `json:"capacity_exceeded"`
+}
+
+// old_user_id - снятый ревьюер (первый из old_reviewers, если на пулл реквесте их было несколько),
+// old_reviewers - все снятые ревьюеры пулл реквеста
+type ReviewReassignment struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	OldUserID         string   `json:"old_user_id"`
+	OldReviewers      []string `json:"old_reviewers"`
+	NewReviewers      []string `json:"new_reviewers"`
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty"`
+}
+
+// Период отсутствия пользователя (в это время он не назначается ревьюером)
+type Absence struct {
+	AbsenceID int       `json:"absence_id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
 }
 
 // PR
@@ -39,6 +167,114 @@ type PullRequest struct {
 	AssignedReviewers []string `json:"assigned_reviewers"`
 	CreatedAt         string   `json:"created_at"`
 	MergedAt          string   `json:"merged_at"`
+	ChangedFiles      []string `json:"changed_files,omitempty"`
+	RequiredTags      []string `json:"required_tags,omitempty"`
+	OwnerReviewers    []string `json:"owner_reviewers,omitempty"`
+	FallbackReviewers []string `json:"fallback_reviewers,omitempty"`
+	RemovedReviewers  []string `json:"removed_reviewers,omitempty"`
+	ReviewerStrategy  string   `json:"reviewer_strategy,omitempty"`
+	Draft             bool     `json:"draft,omitempty"`
+	Reviews           []Review `json:"reviews,omitempty"`
+	Version           int      `json:"version"`
+	ExpectedVersion   *int     `json:"-"` // Версия из If-Match (nil - без проверки)
+}
+
+// Ожидаемая версия для слабого валидатора в If-Match (W/"..."): для If-Match нужно сильное сравнение
+// (RFC 9110), поэтому такой заголовок не совпадает ни с одной версией (версии начинаются с 1)
+const WeakExpectedVersion = 0
+
+// Статусы пулл реквеста
+const (
+	StatusDraft  = "DRAFT"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+// Решение ревьюера по пулл реквесту
+type Review struct {
+	UserID  string `json:"user_id"`
+	Verdict string `json:"verdict"`
+}
+
+// Фильтры и пагинация списка пулл реквестов (пустые поля и nil не фильтруют)
+type PullRequestFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	AuthorTeam  string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	Order       string
+	Limit       int
+	After       *PullRequestCursor
+}
+
+// Позиция в списке пулл реквестов (сортировка по created_at, затем по pull_request_id)
+type PullRequestCursor struct {
+	CreatedAt     time.Time
+	PullRequestID string
+}
+
+type PullRequestList struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+// Правило владения кодом в стиле CODEOWNERS: шаблон пути -> команда или пользователь
+// (для файла действует последнее подходящее правило; require_lead - из команды назначается лид)
+type CodeOwnerRule struct {
+	Pattern     string `json:"pattern"`
+	TeamName    string `json:"team_name,omitempty"`
+	UserID      string `json:"user_id,omitempty"`
+	RequireLead bool   `json:"require_lead,omitempty"`
+}
+
+type CodeOwners struct {
+	Rules []CodeOwnerRule `json:"rules"`
+}
+
+// Обязательные ревьюеры пулл реквеста по правилам владения кодом:
+// пользователи назначаются напрямую, из каждой команды назначается один ревьюер
+// (из команд LeadTeamNames - лид, LeadTeamNames входят в TeamNames)
+type RequiredReviewers struct {
+	UserIDs       []string
+	TeamNames     []string
+	LeadTeamNames []string
+}
+
+// Событие истории пулл реквеста (user_id - ревьюер, которого касается событие,
+// old_user_id - снятый ревьюер при переназначении, actor - инициатор операции)
+type PullRequestEvent struct {
+	EventID       int64     `json:"event_id"`
+	PullRequestID string    `json:"pull_request_id"`
+	EventType     string    `json:"event_type"`
+	UserID        string    `json:"user_id,omitempty"`
+	OldUserID     string    `json:"old_user_id,omitempty"`
+	FromStatus    string    `json:"from_status,omitempty"`
+	ToStatus      string    `json:"to_status,omitempty"`
+	Verdict       string    `json:"verdict,omitempty"`
+	Actor         string    `json:"actor,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PullRequestHistory struct {
+	PullRequestID string             `json:"pull_request_id"`
+	Events        []PullRequestEvent `json:"events"`
+}
+
+// Запрос с заголовком Idempotency-Key и сохраненный ответ на него
+// (fingerprint - метод, путь и хеш тела, If-Match и X-Actor-ID; StatusCode 0 - ответа еще нет;
+// ReservedAt - время резерва ключа этим запросом, по нему сохраняется ответ и снимается резерв)
+type IdempotentRequest struct {
+	Key         string
+	Fingerprint string
+	ReservedAt  time.Time
+	StatusCode  int
+	Header      map[string][]string
+	Body        []byte
 }
 
 type PullRequestShort struct {
@@ -48,6 +284,19 @@ type PullRequestShort struct {
 	Status          string `json:"status"`
 }
 
+// Кандидат в ревьюеры (активный участник команды и количество OPEN пулл реквестов, которые он уже ревьюит)
+// MaxOpenReviews - лимит OPEN ревью (nil - без лимита, 0 - не назначается), MatchedTags - количество тегов пулл реквеста, которые есть у кандидата,
+// Role - роль кандидата в команде TeamName (наблюдатели в кандидаты не попадают)
+type ReviewerCandidate struct {
+	UserID         string   `json:"user_id"`
+	TeamName       string   `json:"team_name"`
+	Role           string   `json:"role"`
+	OpenReviews    int      `json:"open_reviews"`
+	MaxOpenReviews *int     `json:"max_open_reviews"`
+	Tags           []string `json:"tags"`
+	MatchedTags    int      `json:"matched_tags"`
+}
+
 // Активность - добавил от себя
 // Пользователь, сколько пулл реквестов ревьюит, сколько из них MERGED и сколько из них OPEN
 type UserActivity struct {
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+type CodeOwnersRepository interface {
+	CodeOwnersSet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse
+	CodeOwnersGet(ctx context.Context) (*models.ErrorResponse, []models.CodeOwnerRule)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+type IdempotencyRepository interface {
+	IdempotencyBegin(ctx context.Context, request *models.IdempotentRequest, ttl, lockTimeout time.Duration) *models.ErrorResponse
+	IdempotencySave(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse
+	IdempotencyRelease(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse
+}
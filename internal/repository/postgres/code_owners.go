@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/pkg"
+)
+
+// Код ошибки postgres при нарушении внешнего ключа
+const foreignKeyViolation = "23503"
+
+type CodeOwnersRepository struct {
+	db *sql.DB
+}
+
+func NewCodeOwnersRepository(addressToConnectToPSQL string) (*CodeOwnersRepository, error) {
+	db, err := pkg.ConnectToPSQL(addressToConnectToPSQL)
+	if err != nil {
+		log.Printf("failed to connect to db: %v\n", err)
+		return nil, fmt.Errorf("repository: postgres: NewCodeOwnersRepository: %v", err)
+	}
+
+	return &CodeOwnersRepository{db: db}, nil
+}
+
+func (cr *CodeOwnersRepository) CodeOwnersSet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: CodeOwnersSet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Правила заменяются целиком
+
+	queryDeleteRules := "DELETE FROM code_owners;"
+	if _, err := tx.ExecContext(ctx, queryDeleteRules); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CodeOwnersSet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	patterns := make([]string, len(codeOwners.Rules))
+	teamNames := make([]sql.NullString, len(codeOwners.Rules))
+	userIDs := make([]sql.NullString, len(codeOwners.Rules))
+	requireLeads := make([]bool, len(codeOwners.Rules))
+	for i, rule := range codeOwners.Rules {
+		patterns[i] = rule.Pattern
+		teamNames[i] = sql.NullString{String: rule.TeamName, Valid: rule.TeamName != ""}
+		userIDs[i] = sql.NullString{String: rule.UserID, Valid: rule.UserID != ""}
+		requireLeads[i] = rule.RequireLead
+	}
+
+	queryInsertRules := `
+	INSERT INTO code_owners (position, pattern, team_name, user_id, require_lead)
+	SELECT r_position, r_pattern, r_team, r_user, r_lead
+	FROM unnest($1::varchar[], $2::varchar[], $3::varchar[], $4::boolean[]) WITH ORDINALITY AS t(r_pattern, r_team, r_user, r_lead, r_position);
+	`
+	_, err = tx.ExecContext(ctx, queryInsertRules, pq.Array(patterns), pq.Array(teamNames), pq.Array(userIDs), pq.Array(requireLeads))
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "owner team or user not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CodeOwnersSet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: CodeOwnersSet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (cr *CodeOwnersRepository) CodeOwnersGet(ctx context.Context) (*models.ErrorResponse, []models.CodeOwnerRule) {
+	queryGetRules := "SELECT pattern, COALESCE(team_name, ''), COALESCE(user_id, ''), require_lead FROM code_owners ORDER BY position;"
+	rows, err := cr.db.QueryContext(ctx, queryGetRules)
+	if err != nil {
+		log.Printf("repository: postgres: CodeOwnersGet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+	defer rows.Close()
+
+	rules := make([]models.CodeOwnerRule, 0)
+	for rows.Next() {
+		var rule models.CodeOwnerRule
+
+		if err := rows.Scan(&rule.Pattern, &rule.TeamName, &rule.UserID, &rule.RequireLead); err != nil {
+			log.Printf("repository: postgres: CodeOwnersGet: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return nil, rules
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+// Типы событий истории пулл реквеста
+const (
+	EventCreated       = "created"
+	EventAssigned      = "assigned"
+	EventUnassigned    = "unassigned"
+	EventReassigned    = "reassigned"
+	EventReviewed      = "reviewed"
+	EventMerged        = "merged"
+	EventStatusChanged = "status_changed"
+)
+
+// Запись событий в историю (в той же транзакции, что и сама операция). Инициатор берется из контекста
+func recordEvents(ctx context.Context, q querier, events []models.PullRequestEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	nullString := func(value string) sql.NullString {
+		return sql.NullString{String: value, Valid: value != ""}
+	}
+
+	pullRequestIDs := make([]string, len(events))
+	eventTypes := make([]string, len(events))
+	userIDs := make([]sql.NullString, len(events))
+	oldUserIDs := make([]sql.NullString, len(events))
+	fromStatuses := make([]sql.NullString, len(events))
+	toStatuses := make([]sql.NullString, len(events))
+	verdicts := make([]sql.NullString, len(events))
+	for i, event := range events {
+		pullRequestIDs[i] = event.PullRequestID
+		eventTypes[i] = event.EventType
+		userIDs[i] = nullString(event.UserID)
+		oldUserIDs[i] = nullString(event.OldUserID)
+		fromStatuses[i] = nullString(event.FromStatus)
+		toStatuses[i] = nullString(event.ToStatus)
+		verdicts[i] = nullString(event.Verdict)
+	}
+
+	queryInsertEvents := `
+	INSERT INTO pr_events (pull_request_id, event_type, user_id, old_user_id, from_status, to_status, verdict, actor)
+	SELECT e_pr, e_type, e_user, e_old_user, e_from, e_to, e_verdict, $8
+	FROM unnest($1::varchar[], $2::varchar[], $3::varchar[], $4::varchar[], $5::varchar[], $6::varchar[], $7::varchar[])
+		WITH ORDINALITY AS t(e_pr, e_type, e_user, e_old_user, e_from, e_to, e_verdict, e_position)
+	ORDER BY e_position;
+	`
+	_, err := q.ExecContext(ctx, queryInsertEvents,
+		pq.Array(pullRequestIDs), pq.Array(eventTypes), pq.Array(userIDs), pq.Array(oldUserIDs),
+		pq.Array(fromStatuses), pq.Array(toStatuses), pq.Array(verdicts), nullString(models.ActorFromContext(ctx)))
+	return err
+}
+
+// События назначения или снятия ревьюеров (eventType - assigned или unassigned)
+func reviewerEvents(pullRequestID, eventType string, userIDs []string) []models.PullRequestEvent {
+	events := make([]models.PullRequestEvent, 0, len(userIDs))
+	for _, userID := range userIDs {
+		events = append(events, models.PullRequestEvent{
+			PullRequestID: pullRequestID,
+			EventType:     eventType,
+			UserID:        userID,
+		})
+	}
+
+	return events
+}
+
+// События замены ревьюеров: старые и новые ревьюеры сопоставляются по порядку (reassigned),
+// оставшиеся без пары записываются как снятые или назначенные
+func replacementEvents(pullRequestID string, oldReviewers, newReviewers []string) []models.PullRequestEvent {
+	paired := min(len(oldReviewers), len(newReviewers))
+
+	events := make([]models.PullRequestEvent, 0, max(len(oldReviewers), len(newReviewers)))
+	for i := range paired {
+		events = append(events, models.PullRequestEvent{
+			PullRequestID: pullRequestID,
+			EventType:     EventReassigned,
+			UserID:        newReviewers[i],
+			OldUserID:     oldReviewers[i],
+		})
+	}
+	events = append(events, reviewerEvents(pullRequestID, EventUnassigned, oldReviewers[paired:])...)
+	events = append(events, reviewerEvents(pullRequestID, EventAssigned, newReviewers[paired:])...)
+
+	return events
+}
+
+// Событие смены статуса пулл реквеста
+func statusEvent(pullRequestID, eventType, fromStatus, toStatus string) models.PullRequestEvent {
+	return models.PullRequestEvent{
+		PullRequestID: pullRequestID,
+		EventType:     eventType,
+		FromStatus:    fromStatus,
+		ToStatus:      toStatus,
+	}
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestReplacementEvents(t *testing.T) {
+	reassigned := func(oldUserID, userID string) models.PullRequestEvent {
+		return models.PullRequestEvent{PullRequestID: "pr-1", EventType: EventReassigned, UserID: userID, OldUserID: oldUserID}
+	}
+	reviewer := func(eventType, userID string) models.PullRequestEvent {
+		return models.PullRequestEvent{PullRequestID: "pr-1", EventType: eventType, UserID: userID}
+	}
+
+	tests := []struct {
+		name         string
+		oldReviewers []string
+		newReviewers []string
+		want         []models.PullRequestEvent
+	}{
+		{
+			name: "nothing changed",
+			want: []models.PullRequestEvent{},
+		},
+		{
+			name:         "one for one",
+			oldReviewers: []string{"u1"},
+			newReviewers: []string{"u2"},
+			want:         []models.PullRequestEvent{reassigned("u1", "u2")},
+		},
+		{
+			name:         "paired in order",
+			oldReviewers: []string{"u1", "u2"},
+			newReviewers: []string{"u3", "u4"},
+			want:         []models.PullRequestEvent{reassigned("u1", "u3"), reassigned("u2", "u4")},
+		},
+		{
+			name:         "fewer new reviewers",
+			oldReviewers: []string{"u1", "u2"},
+			newReviewers: []string{"u3"},
+			want:         []models.PullRequestEvent{reassigned("u1", "u3"), reviewer(EventUnassigned, "u2")},
+		},
+		{
+			name:         "more new reviewers",
+			oldReviewers: []string{"u1"},
+			newReviewers: []string{"u3", "u4"},
+			want:         []models.PullRequestEvent{reassigned("u1", "u3"), reviewer(EventAssigned, "u4")},
+		},
+		{
+			name:         "only removed",
+			oldReviewers: []string{"u1", "u2"},
+			want:         []models.PullRequestEvent{reviewer(EventUnassigned, "u1"), reviewer(EventUnassigned, "u2")},
+		},
+		{
+			name:         "only assigned",
+			newReviewers: []string{"u3"},
+			want:         []models.PullRequestEvent{reviewer(EventAssigned, "u3")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replacementEvents("pr-1", tt.oldReviewers, tt.newReviewers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replacementEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
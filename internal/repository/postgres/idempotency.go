@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/pkg"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(addressToConnectToPSQL string) (*IdempotencyRepository, error) {
+	db, err := pkg.ConnectToPSQL(addressToConnectToPSQL)
+	if err != nil {
+		log.Printf("failed to connect to db: %v\n", err)
+		return nil, fmt.Errorf("repository: postgres: NewIdempotencyRepository: %v", err)
+	}
+
+	return &IdempotencyRepository{db: db}, nil
+}
+
+// idempotencyBeginAttempts - сколько раз пробуем зарезервировать ключ, если его освобождают между запросами
+const idempotencyBeginAttempts = 3
+
+func (ir *IdempotencyRepository) IdempotencyBegin(ctx context.Context, request *models.IdempotentRequest, ttl, lockTimeout time.Duration) *models.ErrorResponse {
+	// Резервируем ключ (устаревший ключ или зависший резерв без ответа перезаписывается, как будто его не было)
+
+	queryReserve := `
+	INSERT INTO idempotency_keys (idempotency_key, fingerprint)
+	VALUES ($1, $2)
+	ON CONFLICT (idempotency_key) DO UPDATE
+	SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, response_header = NULL, response_body = NULL, created_at = NOW()
+	WHERE
+		idempotency_keys.created_at < NOW() - make_interval(secs => $3) OR
+		(idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < NOW() - make_interval(secs => $4))
+	RETURNING created_at;
+	`
+
+	// Ключ уже занят: отдаем сохраненный ответ, если он есть и запрос тот же
+
+	queryGetResponse := "SELECT fingerprint, status_code, response_header, response_body FROM idempotency_keys WHERE idempotency_key = $1;"
+
+	var (
+		fingerprint string
+		statusCode  sql.NullInt64
+		header      []byte
+		body        []byte
+	)
+
+	found := false
+	for range idempotencyBeginAttempts {
+		err := ir.db.QueryRowContext(ctx, queryReserve, request.Key, request.Fingerprint, ttl.Seconds(), lockTimeout.Seconds()).Scan(&request.ReservedAt)
+		if err == nil {
+			return nil
+		} else if err != sql.ErrNoRows {
+			log.Printf("repository: postgres: IdempotencyBegin: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+
+		err = ir.db.QueryRowContext(ctx, queryGetResponse, request.Key).Scan(&fingerprint, &statusCode, &header, &body)
+		if err == nil {
+			found = true
+			break
+		} else if err != sql.ErrNoRows {
+			log.Printf("repository: postgres: IdempotencyBegin: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+
+		// Ключ успели освободить между запросами - пробуем зарезервировать заново
+	}
+
+	// Ключ все время перехватывают параллельные запросы - считаем, что он занят
+	if !found {
+		return &models.ErrorResponse{
+			Code:    codes.ErrIdempotencyBusy,
+			Message: "request with this idempotency key is in progress",
+		}
+	}
+
+	if fingerprint != request.Fingerprint {
+		return &models.ErrorResponse{
+			Code:    codes.ErrIdempotencyReused,
+			Message: "idempotency key was used with another request",
+		}
+	}
+
+	if !statusCode.Valid {
+		return &models.ErrorResponse{
+			Code:    codes.ErrIdempotencyBusy,
+			Message: "request with this idempotency key is in progress",
+		}
+	}
+
+	if err := json.Unmarshal(header, &request.Header); err != nil {
+		log.Printf("repository: postgres: IdempotencyBegin: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	request.StatusCode = int(statusCode.Int64)
+	request.Body = body
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) IdempotencySave(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse {
+	header, err := json.Marshal(request.Header)
+	if err != nil {
+		log.Printf("repository: postgres: IdempotencySave: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Ответ сохраняется, только если резерв все еще наш (не был перехвачен после IdempotencyLockTimeout)
+
+	querySaveResponse := `
+	UPDATE idempotency_keys
+	SET status_code = $3, response_header = $4, response_body = $5
+	WHERE idempotency_key = $1 AND created_at = $2 AND status_code IS NULL;
+	`
+	_, err = ir.db.ExecContext(ctx, querySaveResponse, request.Key, request.ReservedAt, request.StatusCode, header, request.Body)
+	if err != nil {
+		log.Printf("repository: postgres: IdempotencySave: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) IdempotencyRelease(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse {
+	queryRelease := "DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND created_at = $2 AND status_code IS NULL;"
+	if _, err := ir.db.ExecContext(ctx, queryRelease, request.Key, request.ReservedAt); err != nil {
+		log.Printf("repository: postgres: IdempotencyRelease: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+// Вступление пользователей в команду (если пользователь уже состоит в команде, ничего не меняется).
+// Членство в других командах сохраняется, основная команда (users.team_name) не меняется
+func joinTeam(ctx context.Context, tx *sql.Tx, userIDs []string, teamName string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	queryJoinTeam := `
+	INSERT INTO team_memberships (user_id, team_name)
+	SELECT u_id, $2 FROM unnest($1::varchar[]) AS t(u_id)
+	WHERE NOT EXISTS (
+		SELECT 1 FROM team_memberships m
+		WHERE m.user_id = t.u_id AND m.team_name = $2 AND m.left_at IS NULL
+	);
+	`
+	_, err := tx.ExecContext(ctx, queryJoinTeam, pq.Array(userIDs), teamName)
+	return err
+}
+
+// Выход пользователей из команды (закрытие текущего членства)
+func leaveTeam(ctx context.Context, tx *sql.Tx, userIDs []string, teamName string) error {
+	queryLeaveTeam := `
+	UPDATE team_memberships SET left_at = NOW()
+	WHERE user_id = ANY($1::varchar[]) AND team_name = $2 AND left_at IS NULL;
+	`
+	_, err := tx.ExecContext(ctx, queryLeaveTeam, pq.Array(userIDs), teamName)
+	return err
+}
+
+// Роли пользователей в команде (roles[i] - роль userIDs[i], меняются только текущие членства)
+func setMemberRoles(ctx context.Context, tx *sql.Tx, teamName string, userIDs, roles []string) error {
+	querySetRoles := `
+	UPDATE team_memberships m SET role = t.u_role
+	FROM unnest($2::varchar[], $3::varchar[]) AS t(u_id, u_role)
+	WHERE m.user_id = t.u_id AND m.team_name = $1 AND m.left_at IS NULL;
+	`
+	_, err := tx.ExecContext(ctx, querySetRoles, teamName, pq.Array(userIDs), pq.Array(roles))
+	return err
+}
+
+// Смена основной команды пользователей: членство в прежней основной команде закрывается,
+// в новой открывается (дополнительные членства не меняются)
+func setPrimaryTeam(ctx context.Context, tx *sql.Tx, userIDs []string, teamName string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	queryLeavePrimaryTeam := `
+	UPDATE team_memberships m SET left_at = NOW()
+	FROM users u
+	WHERE
+		u.user_id = m.user_id AND
+		u.user_id = ANY($1::varchar[]) AND
+		m.team_name = u.team_name AND
+		m.left_at IS NULL AND
+		u.team_name <> $2;
+	`
+	if _, err := tx.ExecContext(ctx, queryLeavePrimaryTeam, pq.Array(userIDs), teamName); err != nil {
+		return err
+	}
+
+	queryUpdateUsersTeam := "UPDATE users SET team_name = $2 WHERE user_id = ANY($1::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryUpdateUsersTeam, pq.Array(userIDs), teamName); err != nil {
+		return err
+	}
+
+	return joinTeam(ctx, tx, userIDs, teamName)
+}
+
+// История членства пользователя в командах (от ранних к поздним)
+func getMemberships(ctx context.Context, q querier, userID string) ([]models.TeamMembership, error) {
+	queryMemberships := `
+	SELECT team_name, role, joined_at, left_at
+	FROM team_memberships
+	WHERE user_id = $1
+	ORDER BY joined_at, team_membership_id;
+	`
+	rows, err := q.QueryContext(ctx, queryMemberships, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := make([]models.TeamMembership, 0)
+	for rows.Next() {
+		var (
+			membership models.TeamMembership
+			leftAt     sql.NullTime
+		)
+
+		if err := rows.Scan(&membership.TeamName, &membership.Role, &membership.JoinedAt, &leftAt); err != nil {
+			return nil, err
+		}
+		if leftAt.Valid {
+			membership.LeftAt = &leftAt.Time
+		}
+
+		memberships = append(memberships, membership)
+	}
+
+	return memberships, rows.Err()
+}
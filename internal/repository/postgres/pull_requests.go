@@ -3,17 +3,20 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/tousart/avitotest/internal/codes"
 	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
 	"github.com/tousart/avitotest/pkg"
 )
 
-const StatusMerged = "MERGED"
+const OrderAsc = "asc"
 
 type PullRequestsRepository struct {
 	db *sql.DB
@@ -29,211 +32,1302 @@ func NewPullRequestsRepository(addressToConnectToPSQL string) (*PullRequestsRepo
 	return &PullRequestsRepository{db: db}, nil
 }
 
-func (pr *PullRequestsRepository) PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, []string) {
+func (pr *PullRequestsRepository) PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest, required models.RequiredReviewers, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, *time.Time, []string, []string, []string, int) {
 	// Начинаем транзакцию
 
 	tx, err := pr.db.BeginTx(ctx, nil)
 	if err != nil {
 		log.Printf("repository: postgres: PullRequestCreate: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Проверка на существование пулл реквеста
+
+	var existsPullRequest bool
+	queryPullRequestExists := "SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = $1);"
+	err = tx.QueryRowContext(ctx, queryPullRequestExists, pullRequest.PullRequestID).Scan(&existsPullRequest)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: queryPullRequestExists: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	} else if existsPullRequest {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrPRExists,
+			Message: "pull request already exists",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Проверка на существование автора
+
+	var (
+		authorsTeam    string
+		reviewersCount int
+	)
+	queryAuthorExists := "SELECT u.team_name, t.reviewers_count FROM users u JOIN teams t USING(team_name) WHERE u.user_id = $1;"
+	err = tx.QueryRowContext(ctx, queryAuthorExists, pullRequest.AuthorID).Scan(&authorsTeam, &reviewersCount)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "author not found",
+		}, nil, nil, nil, nil, 0
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: queryAuthorExists: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Добавление пулл реквеста в таблицу (и получение created_at и начальной версии)
+
+	var (
+		createdAt time.Time
+		version   int
+	)
+	queryInsertPR := `
+	INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
+	VALUES ($1, $2, $3, $4)
+	RETURNING created_at, version;
+	`
+	err = tx.QueryRowContext(ctx, queryInsertPR,
+		pullRequest.PullRequestID, pullRequest.PullRequestName, pullRequest.AuthorID, pullRequest.Status).Scan(&createdAt, &version)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: queryInsertPR: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Добавление тегов пулл реквеста (по ним выбираются ревьюеры)
+
+	queryInsertTags := "INSERT INTO pr_tags (pull_request_id, tag) SELECT $1, unnest($2::varchar[]);"
+	_, err = tx.ExecContext(ctx, queryInsertTags, pullRequest.PullRequestID, pq.Array(pullRequest.RequiredTags))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: queryInsertTags: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Измененные файлы сохраняются, чтобы назначить владельцев кода, когда черновик станет готов к ревью
+
+	queryInsertFiles := "INSERT INTO pr_files (pull_request_id, path) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING;"
+	_, err = tx.ExecContext(ctx, queryInsertFiles, pullRequest.PullRequestID, pq.Array(pullRequest.ChangedFiles))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: queryInsertFiles: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Черновику ревьюеры не назначаются
+
+	var reviewers, ownerReviewers, fallbackReviewers []string
+	if pullRequest.Status != models.StatusDraft {
+		var capacityBlocked bool
+
+		reviewers, ownerReviewers, fallbackReviewers, capacityBlocked, err = assignReviewers(ctx, tx, pullRequest.PullRequestID, pullRequest.AuthorID, authorsTeam, reviewersCount, required, selectReviewers)
+		if errors.Is(err, errNoLeadCandidate) {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrNoCandidate,
+				Message: err.Error(),
+			}, nil, nil, nil, nil, 0
+		} else if err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestCreate: assignReviewers: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil, nil, 0
+		}
+
+		// Если назначить не удалось никого и причина в лимитах, пулл реквест не создается
+
+		if len(reviewers) == 0 && capacityBlocked {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrCapacityExceeded,
+				Message: "all candidates reached max open reviews",
+			}, nil, nil, nil, nil, 0
+		}
+	}
+
+	// Запись в историю
+
+	if err := recordEvents(ctx, tx, append([]models.PullRequestEvent{statusEvent(pullRequest.PullRequestID, EventCreated, "", pullRequest.Status)}, reviewerEvents(pullRequest.PullRequestID, EventAssigned, reviewers)...)); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestCreate: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestCreate: commit: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil, nil, 0
+	}
+
+	return nil, &createdAt, reviewers, ownerReviewers, fallbackReviewers, version
+}
+
+func (pr *PullRequestsRepository) PullRequestGet(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string, []string, []models.Review, int) {
+	var (
+		createdAt       time.Time
+		mergedAt        sql.NullTime
+		pullRequestName string
+		authorID        string
+		status          string
+		reviewers       []string
+		verdicts        []string
+		version         int
+	)
+
+	queryGetPR := `
+	SELECT
+		pr.pull_request_name,
+		pr.author_id,
+		pr.status,
+		pr.created_at,
+		pr.merged_at,
+		pr.version,
+		ARRAY(SELECT r.user_id FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id ORDER BY r.pr_reviewers_id) AS reviewers,
+		ARRAY(SELECT COALESCE(r.verdict::text, '') FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id ORDER BY r.pr_reviewers_id) AS verdicts
+	FROM pull_requests pr
+	WHERE pr.pull_request_id = $1;
+	`
+	err := pr.db.QueryRowContext(ctx, queryGetPR, pullRequest.PullRequestID).Scan(
+		&pullRequestName, &authorID, &status, &createdAt, &mergedAt, &version, pq.Array(&reviewers), pq.Array(&verdicts))
+	if err == sql.ErrNoRows {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "pull request not found",
+		}, nil, nil, "", "", "", nil, nil, 0
+	} else if err != nil {
+		log.Printf("repository: postgres: PullRequestGet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", nil, nil, 0
+	}
+
+	// Решения ревьюеров (те, кто еще не отправил решение, не попадают в список)
+
+	reviews := make([]models.Review, 0)
+	for i, verdict := range verdicts {
+		if verdict != "" {
+			reviews = append(reviews, models.Review{UserID: reviewers[i], Verdict: verdict})
+		}
+	}
+
+	if !mergedAt.Valid {
+		return nil, &createdAt, nil, pullRequestName, authorID, status, reviewers, reviews, version
+	}
+
+	return nil, &createdAt, &mergedAt.Time, pullRequestName, authorID, status, reviewers, reviews, version
+}
+
+func (pr *PullRequestsRepository) PullRequestList(ctx context.Context, filter *models.PullRequestFilter) (*models.ErrorResponse, []repository.PullRequestRow, bool) {
+	// Направление сортировки подставляется в запрос только из фиксированного набора
+
+	direction, comparison := "DESC", "<"
+	if filter.Order == OrderAsc {
+		direction, comparison = "ASC", ">"
+	}
+
+	var (
+		afterCreatedAt     *time.Time
+		afterPullRequestID string
+	)
+	if filter.After != nil {
+		afterCreatedAt = &filter.After.CreatedAt
+		afterPullRequestID = filter.After.PullRequestID
+	}
+
+	queryList := `
+	SELECT
+		pr.pull_request_id,
+		pr.pull_request_name,
+		pr.author_id,
+		pr.status,
+		pr.created_at,
+		pr.merged_at,
+		pr.version,
+		ARRAY(SELECT r.user_id FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id ORDER BY r.pr_reviewers_id) AS reviewers
+	FROM pull_requests pr
+	JOIN users a ON a.user_id = pr.author_id
+	WHERE
+		($1 = '' OR pr.status::text = $1) AND
+		($2 = '' OR pr.author_id = $2) AND
+		($3 = '' OR EXISTS (SELECT 1 FROM pr_reviewers r WHERE r.pull_request_id = pr.pull_request_id AND r.user_id = $3)) AND
+		($4 = '' OR a.team_name = $4) AND
+		($5::timestamptz IS NULL OR pr.created_at >= $5) AND
+		($6::timestamptz IS NULL OR pr.created_at < $6) AND
+		($7::timestamptz IS NULL OR pr.merged_at >= $7) AND
+		($8::timestamptz IS NULL OR pr.merged_at < $8) AND
+		($9::timestamptz IS NULL OR (pr.created_at, pr.pull_request_id) ` + comparison + ` ($9, $10))
+	ORDER BY pr.created_at ` + direction + `, pr.pull_request_id ` + direction + `
+	LIMIT $11;
+	`
+
+	// Берем на одну строку больше, чтобы понять, есть ли следующая страница
+
+	rows, err := pr.db.QueryContext(ctx, queryList,
+		filter.Status, filter.AuthorID, filter.ReviewerID, filter.AuthorTeam,
+		filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo,
+		afterCreatedAt, afterPullRequestID, filter.Limit+1)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestList: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, false
+	}
+	defer rows.Close()
+
+	pullRequests := make([]repository.PullRequestRow, 0, filter.Limit)
+	for rows.Next() {
+		var (
+			row      repository.PullRequestRow
+			mergedAt sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&row.PullRequest.PullRequestID, &row.PullRequest.PullRequestName, &row.PullRequest.AuthorID, &row.PullRequest.Status,
+			&row.CreatedAt, &mergedAt, &row.PullRequest.Version, pq.Array(&row.PullRequest.AssignedReviewers)); err != nil {
+			log.Printf("repository: postgres: PullRequestList: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, false
+		}
+
+		if mergedAt.Valid {
+			row.MergedAt = &mergedAt.Time
+		}
+
+		pullRequests = append(pullRequests, row)
+	}
+
+	if len(pullRequests) > filter.Limit {
+		return nil, pullRequests[:filter.Limit], true
+	}
+
+	return nil, pullRequests, false
+}
+
+func (pr *PullRequestsRepository) PullRequestHistory(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, []models.PullRequestEvent) {
+	// Проверка на существование пулл реквеста
+
+	var exists bool
+	queryExists := "SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = $1);"
+	if err := pr.db.QueryRowContext(ctx, queryExists, pullRequest.PullRequestID).Scan(&exists); err != nil {
+		log.Printf("repository: postgres: PullRequestHistory: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	} else if !exists {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "pull request not found",
+		}, nil
+	}
+
+	// События в порядке записи
+
+	queryEvents := `
+	SELECT
+		pr_event_id,
+		pull_request_id,
+		event_type,
+		COALESCE(user_id, ''),
+		COALESCE(old_user_id, ''),
+		COALESCE(from_status, ''),
+		COALESCE(to_status, ''),
+		COALESCE(verdict, ''),
+		COALESCE(actor, ''),
+		created_at
+	FROM pr_events
+	WHERE pull_request_id = $1
+	ORDER BY pr_event_id;
+	`
+	rows, err := pr.db.QueryContext(ctx, queryEvents, pullRequest.PullRequestID)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestHistory: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+	defer rows.Close()
+
+	events := make([]models.PullRequestEvent, 0)
+	for rows.Next() {
+		var event models.PullRequestEvent
+
+		if err := rows.Scan(&event.EventID, &event.PullRequestID, &event.EventType, &event.UserID, &event.OldUserID,
+			&event.FromStatus, &event.ToStatus, &event.Verdict, &event.Actor, &event.CreatedAt); err != nil {
+			log.Printf("repository: postgres: PullRequestHistory: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("repository: postgres: PullRequestHistory: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	return nil, events
+}
+
+func (pr *PullRequestsRepository) PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string, int) {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Проверяем, существует ли пулл реквест, и, если существует, берем его данные (строка блокируется до конца транзакции)
+
+	var (
+		createdAt       time.Time
+		mergedAt        sql.NullTime
+		pullRequestName string
+		authorID        string
+		status          string
+		version         int
+	)
+
+	queryExistsPR := "SELECT pull_request_name, author_id, status, created_at, merged_at, version FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE;"
+	err = tx.QueryRowContext(ctx, queryExistsPR, pullRequest.PullRequestID).Scan(
+		&pullRequestName, &authorID, &status, &createdAt, &mergedAt, &version)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "pull request not found",
+		}, nil, nil, "", "", "", 0
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Если сохраненный статус уже MERGED, то просто возвращаем объект пулл реквеста (как раз это условие реализует идемпотентность:
+	// status и merged_at изменяются только раз, а конкурентный merge ждет блокировку строки и видит уже MERGED).
+	// Версия при этом не проверяется: повторный merge с исходным If-Match должен получить тот же ответ
+
+	if status == models.StatusMerged {
+		tx.Rollback()
+		return nil, &createdAt, &mergedAt.Time, pullRequestName, authorID, status, version
+	}
+
+	if errResp := checkVersion(pullRequest.ExpectedVersion, version); errResp != nil {
+		tx.Rollback()
+		return errResp, nil, nil, "", "", "", 0
+	}
+
+	// Статус мог измениться после проверки в сервисе
+
+	if status != models.StatusOpen {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrInvalidTransition,
+			Message: "pull request status has changed",
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Merge возможен, только если набрано required_approvals одобрений (команды автора) и ни один ревьюер
+	// не запросил изменения
+
+	var (
+		requiredApprovals int
+		approvals         int
+		changesRequested  int
+	)
+
+	queryApprovals := `
+	SELECT
+		t.required_approvals,
+		COUNT(r.user_id) FILTER (WHERE r.verdict = 'APPROVED'),
+		COUNT(r.user_id) FILTER (WHERE r.verdict = 'CHANGES_REQUESTED')
+	FROM users a
+	JOIN teams t ON t.team_name = a.team_name
+	LEFT JOIN pr_reviewers r ON r.pull_request_id = $1
+	WHERE a.user_id = $2
+	GROUP BY t.required_approvals;
+	`
+	err = tx.QueryRowContext(ctx, queryApprovals, pullRequest.PullRequestID, authorID).Scan(
+		&requiredApprovals, &approvals, &changesRequested)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestMerge: queryApprovals: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	if changesRequested > 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrMergeBlocked,
+			Message: "changes requested by reviewers",
+		}, nil, nil, "", "", "", 0
+	}
+
+	if approvals < requiredApprovals {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrMergeBlocked,
+			Message: fmt.Sprintf("not enough approvals: %d of %d", approvals, requiredApprovals),
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Если статус не MERGED (а OPEN), то обновляем статус и фиксируем время (merged_at)
+
+	fromStatus := status
+	queryUpdateStatus := "UPDATE pull_requests SET status = 'MERGED', merged_at = NOW(), version = version + 1 WHERE pull_request_id = $1 RETURNING status, merged_at, version;"
+	err = tx.QueryRowContext(ctx, queryUpdateStatus, pullRequest.PullRequestID).Scan(&status, &mergedAt, &version)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Запись в историю
+
+	if err := recordEvents(ctx, tx, []models.PullRequestEvent{statusEvent(pullRequest.PullRequestID, EventMerged, fromStatus, status)}); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestMerge: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, "", "", "", 0
+	}
+
+	return nil, &createdAt, &mergedAt.Time, pullRequestName, authorID, status, version
+}
+
+func (pr *PullRequestsRepository) PullRequestState(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, string, []string) {
+	var (
+		status       string
+		changedFiles []string
+	)
+
+	queryGetState := `
+	SELECT
+		pr.status,
+		ARRAY(SELECT f.path FROM pr_files f WHERE f.pull_request_id = pr.pull_request_id ORDER BY f.path) AS changed_files
+	FROM pull_requests pr
+	WHERE pr.pull_request_id = $1;
+	`
+	err := pr.db.QueryRowContext(ctx, queryGetState, pullRequest.PullRequestID).Scan(&status, pq.Array(&changedFiles))
+	if err == sql.ErrNoRows {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "pull request not found",
+		}, "", nil
+	} else if err != nil {
+		log.Printf("repository: postgres: PullRequestState: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", nil
+	}
+
+	return nil, status, changedFiles
+}
+
+func (pr *PullRequestsRepository) PullRequestReady(ctx context.Context, pullRequest *models.PullRequest, required models.RequiredReviewers, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, []string, []string) {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestReady: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Перевод черновика в OPEN (строка блокируется до конца транзакции; если статус успели изменить, переход отклоняется)
+
+	var (
+		authorsTeam    string
+		authorID       string
+		reviewersCount int
+	)
+
+	queryUpdateStatus := `
+	UPDATE pull_requests pr SET status = 'OPEN', version = pr.version + 1
+	FROM users a
+	JOIN teams t ON t.team_name = a.team_name
+	WHERE pr.pull_request_id = $1 AND pr.status = 'DRAFT' AND a.user_id = pr.author_id
+	RETURNING pr.author_id, a.team_name, t.reviewers_count;
+	`
+	err = tx.QueryRowContext(ctx, queryUpdateStatus, pullRequest.PullRequestID).Scan(&authorID, &authorsTeam, &reviewersCount)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrInvalidTransition,
+			Message: "pull request is not a draft",
+		}, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReady: queryUpdateStatus: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Назначение ревьюеров так же, как при создании пулл реквеста
+
+	reviewers, ownerReviewers, fallbackReviewers, capacityBlocked, err := assignReviewers(ctx, tx, pullRequest.PullRequestID, authorID, authorsTeam, reviewersCount, required, selectReviewers)
+	if errors.Is(err, errNoLeadCandidate) {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNoCandidate,
+			Message: err.Error(),
+		}, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReady: assignReviewers: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Если назначить не удалось никого и причина в лимитах, пулл реквест остается черновиком
+
+	if len(reviewers) == 0 && capacityBlocked {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrCapacityExceeded,
+			Message: "all candidates reached max open reviews",
+		}, nil, nil
+	}
+
+	// Запись в историю
+
+	if err := recordEvents(ctx, tx, append([]models.PullRequestEvent{statusEvent(pullRequest.PullRequestID, EventStatusChanged, models.StatusDraft, models.StatusOpen)}, reviewerEvents(pullRequest.PullRequestID, EventAssigned, reviewers)...)); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReady: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestReady: commit: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	return nil, ownerReviewers, fallbackReviewers
+}
+
+func (pr *PullRequestsRepository) PullRequestClose(ctx context.Context, pullRequest *models.PullRequest, fromStatus string) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestClose: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Закрытие пулл реквеста, если его статус не изменился с момента проверки перехода
+
+	result, err := tx.ExecContext(ctx, "UPDATE pull_requests SET status = 'CLOSED', version = version + 1 WHERE pull_request_id = $1 AND status = $2;",
+		pullRequest.PullRequestID, fromStatus)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestClose: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestClose: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrInvalidTransition,
+			Message: "pull request status has changed",
+		}
+	}
+
+	// Ревьюеры закрытого пулл реквеста освобождаются
+
+	var removedReviewers []string
+	queryDeleteReviewers := `
+	WITH deleted AS (
+		DELETE FROM pr_reviewers WHERE pull_request_id = $1 RETURNING pr_reviewers_id, user_id
+	)
+	SELECT ARRAY(SELECT user_id FROM deleted ORDER BY pr_reviewers_id);
+	`
+	err = tx.QueryRowContext(ctx, queryDeleteReviewers, pullRequest.PullRequestID).Scan(pq.Array(&removedReviewers))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestClose: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Запись в историю
+
+	if err := recordEvents(ctx, tx, append([]models.PullRequestEvent{statusEvent(pullRequest.PullRequestID, EventStatusChanged, fromStatus, models.StatusClosed)}, reviewerEvents(pullRequest.PullRequestID, EventUnassigned, removedReviewers)...)); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestClose: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestClose: commit: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (pr *PullRequestsRepository) PullRequestReopen(ctx context.Context, pullRequest *models.PullRequest, fromStatus string, required models.RequiredReviewers, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, []string, []string) {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestReopen: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Возврат в OPEN (merged_at сбрасывается), если статус не изменился с момента проверки перехода
+
+	var (
+		authorsTeam    string
+		authorID       string
+		reviewersCount int
+	)
+
+	queryUpdateStatus := `
+	UPDATE pull_requests pr SET status = 'OPEN', merged_at = NULL, version = pr.version + 1
+	FROM users a
+	JOIN teams t ON t.team_name = a.team_name
+	WHERE pr.pull_request_id = $1 AND pr.status = $2 AND a.user_id = pr.author_id
+	RETURNING pr.author_id, a.team_name, t.reviewers_count;
+	`
+	err = tx.QueryRowContext(ctx, queryUpdateStatus, pullRequest.PullRequestID, fromStatus).Scan(&authorID, &authorsTeam, &reviewersCount)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrInvalidTransition,
+			Message: "pull request status has changed",
+		}, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReopen: queryUpdateStatus: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Решения прошлого цикла ревью больше не действуют
+
+	_, err = tx.ExecContext(ctx, "UPDATE pr_reviewers SET verdict = NULL, reviewed_at = NULL WHERE pull_request_id = $1;", pullRequest.PullRequestID)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReopen: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Снятие ревьюеров, которые стали неактивными или перешли в другую команду
+
+	kept, keptTeams, keptLeadTeams, removed, err := dropInvalidReviewers(ctx, tx, pullRequest.PullRequestID, authorsTeam, required)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReopen: dropInvalidReviewers: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Добор ревьюеров до reviewers_count (владельцы кода, которых еще нет среди оставшихся, назначаются первыми).
+	// Если кандидатов не хватает, пулл реквест все равно открывается с теми ревьюерами, что есть
+
+	missing := models.RequiredReviewers{
+		UserIDs: slices.DeleteFunc(slices.Clone(required.UserIDs), func(userID string) bool {
+			return slices.Contains(kept, userID)
+		}),
+		TeamNames: slices.DeleteFunc(slices.Clone(required.TeamNames), func(teamName string) bool {
+			if slices.Contains(required.LeadTeamNames, teamName) {
+				return slices.Contains(keptLeadTeams, teamName)
+			}
+			return slices.Contains(keptTeams, teamName)
+		}),
+		LeadTeamNames: slices.DeleteFunc(slices.Clone(required.LeadTeamNames), func(teamName string) bool {
+			return slices.Contains(keptLeadTeams, teamName)
+		}),
+	}
+
+	newReviewers, _, fallbackReviewers, _, err := assignReviewers(ctx, tx, pullRequest.PullRequestID, authorID, authorsTeam, reviewersCount-len(kept), missing, selectReviewers)
+	if errors.Is(err, errNoLeadCandidate) {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNoCandidate,
+			Message: err.Error(),
+		}, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReopen: assignReviewers: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Запись в историю
+
+	if err := recordEvents(ctx, tx, slices.Concat([]models.PullRequestEvent{statusEvent(pullRequest.PullRequestID, EventStatusChanged, fromStatus, models.StatusOpen)}, replacementEvents(pullRequest.PullRequestID, removed, newReviewers))); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReopen: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestReopen: commit: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
 		}, nil, nil
 	}
 
-	// Проверка на существование пулл реквеста
+	return nil, removed, fallbackReviewers
+}
+
+func (pr *PullRequestsRepository) PullRequestAddReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestAddReviewer: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Проверка пулл реквеста (строка блокируется до конца транзакции)
+
+	var (
+		authorID, authorsTeam, status string
+		version                       int
+		actorIsLead                   bool
+	)
+	queryLockPR := `
+	SELECT
+		pr.author_id,
+		a.team_name,
+		pr.status,
+		pr.version,
+		EXISTS(
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = $2 AND m.team_name = a.team_name AND m.left_at IS NULL AND m.role = 'lead'
+		) AS actor_is_lead
+	FROM pull_requests pr
+	JOIN users a ON a.user_id = pr.author_id
+	WHERE pr.pull_request_id = $1
+	FOR UPDATE OF pr;
+	`
+	err = tx.QueryRowContext(ctx, queryLockPR, pullRequest.PullRequestID, models.ActorFromContext(ctx)).Scan(
+		&authorID, &authorsTeam, &status, &version, &actorIsLead)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "pull request not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestAddReviewer: queryLockPR: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if errResp := checkVersion(pullRequest.ExpectedVersion, version); errResp != nil {
+		tx.Rollback()
+		return errResp
+	}
+
+	if errResp := checkReviewersEditable(status); errResp != nil {
+		tx.Rollback()
+		return errResp
+	}
+
+	// Добавлять ревьюеров вручную может только лид команды автора (инициатор из X-Actor-ID)
 
-	var existsPullRequest bool
-	queryPullRequestExists := "SELECT EXISTS (SELECT 1 FROM pull_requests WHERE pull_request_id = $1);"
-	err = tx.QueryRowContext(ctx, queryPullRequestExists, pullRequest.PullRequestID).Scan(&existsPullRequest)
+	if !actorIsLead {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrForbidden,
+			Message: "only a lead of the author's team can add reviewers",
+		}
+	}
+
+	// Проверка пользователя (если он уже назначен, ничего не меняется)
+
+	errResp, alreadyReviewer, err := checkManualReviewer(ctx, tx, pullRequest.PullRequestID, authorID, authorsTeam, userID)
 	if err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestCreate: queryPullRequestExists: %v\n", err)
+		log.Printf("repository: postgres: PullRequestAddReviewer: checkManualReviewer: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
-	} else if existsPullRequest {
+		}
+	} else if errResp != nil {
+		tx.Rollback()
+		return errResp
+	} else if alreadyReviewer {
 		tx.Rollback()
+		return nil
+	}
+
+	// Добавление ревьюера (если он уже назначен, ничего не меняется)
+
+	queryInsertReviewer := "INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;"
+	result, err := tx.ExecContext(ctx, queryInsertReviewer, pullRequest.PullRequestID, userID)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestAddReviewer: %v\n", err)
 		return &models.ErrorResponse{
-			Code:    codes.ErrPRExists,
-			Message: "pull request already exists",
-		}, nil, nil
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
 	}
 
-	// Проверка на существование автора
+	added, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestAddReviewer: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Новая версия и запись в историю (только если ревьюер действительно добавлен)
+
+	if added > 0 {
+		if _, err := bumpVersion(ctx, tx, pullRequest.PullRequestID); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestAddReviewer: bumpVersion: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+
+		if err := recordEvents(ctx, tx, reviewerEvents(pullRequest.PullRequestID, EventAssigned, []string{userID})); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestAddReviewer: recordEvents: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: PullRequestAddReviewer: commit: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (pr *PullRequestsRepository) PullRequestRemoveReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
 
-	var authorsTeam string
-	queryAuthorExists := "SELECT team_name FROM users WHERE user_id = $1;"
-	err = tx.QueryRowContext(ctx, queryAuthorExists, pullRequest.AuthorID).Scan(&authorsTeam)
+	// Проверка пулл реквеста (строка блокируется до конца транзакции)
+
+	var (
+		status      string
+		version     int
+		actorIsLead bool
+	)
+	queryLockPR := `
+	SELECT
+		pr.status,
+		pr.version,
+		EXISTS(
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = $2 AND m.team_name = a.team_name AND m.left_at IS NULL AND m.role = 'lead'
+		) AS actor_is_lead
+	FROM pull_requests pr
+	JOIN users a ON a.user_id = pr.author_id
+	WHERE pr.pull_request_id = $1
+	FOR UPDATE OF pr;
+	`
+	err = tx.QueryRowContext(ctx, queryLockPR, pullRequest.PullRequestID, models.ActorFromContext(ctx)).Scan(&status, &version, &actorIsLead)
 	if err == sql.ErrNoRows {
 		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
-			Message: "author not found",
-		}, nil, nil
+			Message: "pull request not found",
+		}
 	} else if err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestCreate: queryAuthorExists: %v\n", err)
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
+		}
 	}
 
-	// Добавление пулл реквеста в таблицу (и получение created_at)
+	if errResp := checkVersion(pullRequest.ExpectedVersion, version); errResp != nil {
+		tx.Rollback()
+		return errResp
+	}
 
-	var createdAt time.Time
-	queryInsertPR := `
-	INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status)
-	VALUES ($1, $2, $3, $4)
-	RETURNING created_at;
-	`
-	err = tx.QueryRowContext(ctx, queryInsertPR,
-		pullRequest.PullRequestID, pullRequest.PullRequestName, pullRequest.AuthorID, pullRequest.Status).Scan(&createdAt)
+	if errResp := checkReviewersEditable(status); errResp != nil {
+		tx.Rollback()
+		return errResp
+	}
+
+	// Снимать ревьюеров вручную может только лид команды автора (инициатор из X-Actor-ID)
+
+	if !actorIsLead {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrForbidden,
+			Message: "only a lead of the author's team can remove reviewers",
+		}
+	}
+
+	// Снятие ревьюера без замены
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND user_id = $2;", pullRequest.PullRequestID, userID)
 	if err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestCreate: queryInsertPR: %v\n", err)
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
+		}
 	}
 
-	// Поиск свободных ревьюеров
-
-	queryAssignReviewers := `
-	SELECT user_id FROM users 
-	WHERE team_name = $1 AND is_active = true AND user_id <> $2
-	ORDER BY RANDOM()
-	LIMIT 2;
-	`
-	rows, err := tx.QueryContext(ctx, queryAssignReviewers, authorsTeam, pullRequest.AuthorID)
-	if err != nil && err != sql.ErrNoRows {
+	if affected, err := result.RowsAffected(); err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestCreate: queryAssignReviewers: %v\n", err)
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotAssigned,
+			Message: "user is not a reviewer of this pull request",
+		}
 	}
 
-	reviewers := make([]string, 0)
-	for rows.Next() {
-		var userID string
+	// Новая версия и запись в историю
 
-		if err := rows.Scan(&userID); err != nil {
-			tx.Rollback()
-			log.Printf("repository: postgres: PullRequestCreate: user_id: %v\n", err)
-			return &models.ErrorResponse{
-				Code:    codes.ErrInternal,
-				Message: "internal error",
-			}, nil, nil
+	if _, err := bumpVersion(ctx, tx, pullRequest.PullRequestID); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: bumpVersion: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
 		}
-
-		reviewers = append(reviewers, userID)
 	}
-	rows.Close()
-
-	// Добавление в pr_reviewers значений pull_request_id - user_id
 
-	queryInsertReviewer := "INSERT INTO pr_reviewers (pull_request_id, user_id) SELECT $1, unnest($2::varchar[]);"
-	_, err = tx.ExecContext(ctx, queryInsertReviewer, pullRequest.PullRequestID, pq.Array(reviewers))
-	if err != nil {
+	if err := recordEvents(ctx, tx, reviewerEvents(pullRequest.PullRequestID, EventUnassigned, []string{userID})); err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestCreate: queryInsertReviewer: %v\n", err)
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: recordEvents: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
+		}
 	}
 
 	// Коммит
 
 	if err := tx.Commit(); err != nil {
-		log.Printf("repository: postgres: PullRequestCreate: commit: %v\n", err)
+		log.Printf("repository: postgres: PullRequestRemoveReviewer: commit: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil
+		}
+	}
+
+	return nil
+}
+
+// Проверка версии из If-Match (nil - проверка не нужна)
+func checkVersion(expected *int, current int) *models.ErrorResponse {
+	if expected == nil || *expected == current {
+		return nil
+	}
+
+	if *expected == models.WeakExpectedVersion {
+		return &models.ErrorResponse{
+			Code:    codes.ErrVersionMismatch,
+			Message: "weak entity tags never match If-Match",
+		}
+	}
+
+	return &models.ErrorResponse{
+		Code:    codes.ErrVersionMismatch,
+		Message: fmt.Sprintf("pull request version is %d, expected %d", current, *expected),
 	}
+}
+
+// Увеличение версии пулл реквеста после изменения его ревьюеров
+func bumpVersion(ctx context.Context, q querier, pullRequestID string) (int, error) {
+	var version int
+	queryBumpVersion := "UPDATE pull_requests SET version = version + 1 WHERE pull_request_id = $1 RETURNING version;"
+	err := q.QueryRowContext(ctx, queryBumpVersion, pullRequestID).Scan(&version)
+	return version, err
+}
 
-	return nil, &createdAt, reviewers
+// Ревьюеров можно менять вручную только у OPEN пулл реквестов
+func checkReviewersEditable(status string) *models.ErrorResponse {
+	switch status {
+	case models.StatusMerged:
+		return &models.ErrorResponse{
+			Code:    codes.ErrPRMerged,
+			Message: "pull request is merged",
+		}
+	case models.StatusOpen:
+		return nil
+	default:
+		return &models.ErrorResponse{
+			Code:    codes.ErrInvalidTransition,
+			Message: "pull request is not open",
+		}
+	}
 }
 
-func (pr *PullRequestsRepository) PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string) {
+func (pr *PullRequestsRepository) PullRequestReview(ctx context.Context, pullRequest *models.PullRequest, review *models.Review) *models.ErrorResponse {
 	// Начинаем транзакцию
 
 	tx, err := pr.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		log.Printf("repository: postgres: PullRequestReview: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil, "", "", ""
+		}
 	}
 
-	// Проверяем, существует ли пулл реквест, и, если существует, берем его данные
+	// Проверка на существование пулл реквеста и его статуса
 
 	var (
-		createdAt       time.Time
-		mergedAt        sql.NullTime
-		pullRequestName string
-		authorID        string
-		status          string
+		status  string
+		version int
 	)
-
-	queryExistsPR := "SELECT pull_request_name, author_id, status, created_at, merged_at FROM pull_requests WHERE pull_request_id = $1;"
-	err = tx.QueryRowContext(ctx, queryExistsPR, pullRequest.PullRequestID).Scan(
-		&pullRequestName, &authorID, &status, &createdAt, &mergedAt)
+	queryStatus := "SELECT status, version FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE;"
+	err = tx.QueryRowContext(ctx, queryStatus, pullRequest.PullRequestID).Scan(&status, &version)
 	if err == sql.ErrNoRows {
 		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
 			Message: "pull request not found",
-		}, nil, nil, "", "", ""
+		}
 	} else if err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		log.Printf("repository: postgres: PullRequestReview: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil, "", "", ""
+		}
+	}
+
+	if errResp := checkVersion(pullRequest.ExpectedVersion, version); errResp != nil {
+		tx.Rollback()
+		return errResp
 	}
 
-	// Если пулл реквест уже MERGED, то просто возвращаем объект пулл реквеста (как раз это условие реализует идемпотентность: status и merged_at изменяются только раз)
+	// Решения принимаются только по OPEN пулл реквестам (как и изменение ревьюеров)
 
-	if pullRequest.Status == StatusMerged {
-		return nil, &createdAt, &mergedAt.Time, pullRequestName, authorID, status
+	if errResp := checkReviewersEditable(status); errResp != nil {
+		tx.Rollback()
+		return errResp
 	}
 
-	// Если статус не MERGED (а OPEN), то обновляем статус и фиксируем время (merged_at)
+	// Сохранение решения (повторное решение заменяет предыдущее)
 
-	queryUpdateStatus := "UPDATE pull_requests SET status = 'MERGED', merged_at = NOW() WHERE pull_request_id = $1 RETURNING status, merged_at;"
-	err = tx.QueryRowContext(ctx, queryUpdateStatus, pullRequest.PullRequestID).Scan(&status, &mergedAt)
+	querySetVerdict := "UPDATE pr_reviewers SET verdict = $3, reviewed_at = NOW() WHERE pull_request_id = $1 AND user_id = $2;"
+	result, err := tx.ExecContext(ctx, querySetVerdict, pullRequest.PullRequestID, review.UserID, review.Verdict)
 	if err != nil {
 		tx.Rollback()
-		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		log.Printf("repository: postgres: PullRequestReview: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReview: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotAssigned,
+			Message: "user is not a reviewer of this pull request",
+		}
+	}
+
+	// Новая версия и запись в историю
+
+	if _, err := bumpVersion(ctx, tx, pullRequest.PullRequestID); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReview: bumpVersion: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	event := models.PullRequestEvent{
+		PullRequestID: pullRequest.PullRequestID,
+		EventType:     EventReviewed,
+		UserID:        review.UserID,
+		Verdict:       review.Verdict,
+	}
+	if err := recordEvents(ctx, tx, []models.PullRequestEvent{event}); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReview: recordEvents: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil, "", "", ""
+		}
 	}
 
 	// Коммит
 
 	if err := tx.Commit(); err != nil {
-		log.Printf("repository: postgres: PullRequestMerge: %v\n", err)
+		log.Printf("repository: postgres: PullRequestReview: commit: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil, nil, "", "", ""
+		}
 	}
 
-	return nil, &createdAt, &mergedAt.Time, pullRequestName, authorID, status
+	return nil
 }
 
-func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID string) (*models.ErrorResponse, string, string, string, []string) {
+func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID, newUserID string, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, string, string, string, []string, []string, int) {
 	// Начинаем транзакцию
 
 	tx, err := pr.db.BeginTx(ctx, nil)
@@ -242,7 +1336,7 @@ func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullR
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
 	// Проверка на существование пулл реквеста
@@ -251,100 +1345,185 @@ func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullR
 	// Проверка статуса пулл реквеста
 
 	var (
-		teamName        string
+		authorsTeam     string
+		oldUserExists   bool
 		authorID        string
 		status          string
 		isReviewer      bool
 		pullRequestName string
+		version         int
+		actorIsLead     bool
 	)
 
 	queryCheck := `
 	SELECT
-		u.team_name,
+		a.team_name,
+		u.user_id IS NOT NULL AS old_user_exists,
 		pr.author_id,
 		pr.status,
 		pr.pull_request_name,
+		pr.version,
 		EXISTS(
 			SELECT 1 FROM pr_reviewers r
 			WHERE r.pull_request_id = $1 AND r.user_id = $2
-		) AS is_reviewer
+		) AS is_reviewer,
+		EXISTS(
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = $3 AND m.team_name = a.team_name AND m.left_at IS NULL AND m.role = 'lead'
+		) AS actor_is_lead
 	FROM pull_requests pr
-	JOIN users u ON u.user_id = $2
-	WHERE pr.pull_request_id = $1;
+	LEFT JOIN users u ON u.user_id = $2
+	JOIN users a ON a.user_id = pr.author_id
+	WHERE pr.pull_request_id = $1
+	FOR UPDATE OF pr;
 	`
 
-	err = tx.QueryRowContext(ctx, queryCheck, pullRequest.PullRequestID, oldUserID).Scan(&teamName, &authorID, &status, &pullRequestName, &isReviewer)
+	err = tx.QueryRowContext(ctx, queryCheck, pullRequest.PullRequestID, oldUserID, models.ActorFromContext(ctx)).Scan(
+		&authorsTeam, &oldUserExists, &authorID, &status, &pullRequestName, &version, &isReviewer, &actorIsLead)
 	if err == sql.ErrNoRows {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
 			Message: "pull request not found",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	} else if err != nil {
+		tx.Rollback()
 		log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
+	}
+
+	if errResp := checkVersion(pullRequest.ExpectedVersion, version); errResp != nil {
+		tx.Rollback()
+		return errResp, "", "", "", nil, nil, 0
 	}
 
-	if teamName == "" {
+	if !oldUserExists {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
 			Message: "old user not found",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
 	if !isReviewer {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotAssigned,
 			Message: "old user is not a reviewer of this pull request",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
-	if status == StatusMerged {
+	if status == models.StatusMerged {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrPRMerged,
 			Message: "pull request is merged",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
-	// Получение нового кандидата
+	// Замена на выбранного пользователя: выбирать ревьюера вручную может только лид команды автора (инициатор из X-Actor-ID).
+	// Новый ревьюер проверяется так же, как в PullRequestAddReviewer, и еще не должен быть ревьюером
 
-	var newUserID string
-	queryNewCandidate := `
-	SELECT user_id FROM users 
-	WHERE 
-		is_active = true AND 
-		user_id <> $1 AND 
-		team_name = $2 AND 
-		user_id NOT IN (SELECT user_id FROM pr_reviewers WHERE pull_request_id = $3) 
-	ORDER BY RANDOM() 
-	LIMIT 1;
-	`
-	err = tx.QueryRowContext(ctx, queryNewCandidate, authorID, teamName, pullRequest.PullRequestID).Scan(&newUserID)
-	if err == sql.ErrNoRows {
+	var newReviewers, fallbackReviewers []string
+	if newUserID != "" {
+		if !actorIsLead {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrForbidden,
+				Message: "only a lead of the author's team can choose a new reviewer",
+			}, "", "", "", nil, nil, 0
+		}
+
+		errResp, alreadyReviewer, err := checkManualReviewer(ctx, tx, pullRequest.PullRequestID, authorID, authorsTeam, newUserID)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestReassign: checkManualReviewer: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, "", "", "", nil, nil, 0
+		} else if errResp != nil {
+			tx.Rollback()
+			if errResp.Code == codes.ErrNotFound {
+				errResp.Message = "new user not found"
+			}
+			return errResp, "", "", "", nil, nil, 0
+		} else if alreadyReviewer {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrNotEligible,
+				Message: "new user is already a reviewer of this pull request",
+			}, "", "", "", nil, nil, 0
+		}
+
+		if err := removeReviewer(ctx, tx, pullRequest.PullRequestID, oldUserID); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, "", "", "", nil, nil, 0
+		}
+
+		queryInsertReviewer := "INSERT INTO pr_reviewers (pull_request_id, user_id) VALUES ($1, $2);"
+		if _, err := tx.ExecContext(ctx, queryInsertReviewer, pullRequest.PullRequestID, newUserID); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, "", "", "", nil, nil, 0
+		}
+		newReviewers = []string{newUserID}
+	} else {
+		// Замена старого ревьюера ровно одним новым из команды автора или ее запасных команд
+		// (если замены нет, ревьюер не снимается)
+
+		newReviewers, fallbackReviewers, err = replaceReviewer(ctx, tx, pullRequest.PullRequestID, oldUserID, authorsTeam, authorID, selectReviewers)
+	}
+	if err == errNoCandidate {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNoCandidate,
 			Message: "no available candidates",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
+	} else if err == errCapacityExceeded {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrCapacityExceeded,
+			Message: "all candidates reached max open reviews",
+		}, "", "", "", nil, nil, 0
 	} else if err != nil {
+		tx.Rollback()
 		log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
-	// Заменяем старого кандидата на нового в этом пулл реквесте
+	// Новая версия и запись в историю
 
-	queryUpdateCandidate := "UPDATE pr_reviewers SET user_id = $1 WHERE user_id = $2 AND pull_request_id = $3;"
-	_, err = tx.ExecContext(ctx, queryUpdateCandidate, newUserID, oldUserID, pullRequest.PullRequestID)
+	version, err = bumpVersion(ctx, tx, pullRequest.PullRequestID)
 	if err != nil {
-		log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReassign: bumpVersion: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
+	}
+
+	if err := recordEvents(ctx, tx, replacementEvents(pullRequest.PullRequestID, []string{oldUserID}, newReviewers)); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: PullRequestReassign: recordEvents: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", "", "", nil, nil, 0
 	}
 
 	// Получение обновленного набора ревьюеров
@@ -352,11 +1531,12 @@ func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullR
 	queryGetReviewers := "SELECT user_id FROM pr_reviewers WHERE pull_request_id = $1;"
 	rows, err := tx.QueryContext(ctx, queryGetReviewers, pullRequest.PullRequestID)
 	if err != nil {
+		tx.Rollback()
 		log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 	defer rows.Close()
 
@@ -365,11 +1545,12 @@ func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullR
 		var userID string
 
 		if err := rows.Scan(&userID); err != nil {
+			tx.Rollback()
 			log.Printf("repository: postgres: PullRequestReassign: %v\n", err)
 			return &models.ErrorResponse{
 				Code:    codes.ErrInternal,
 				Message: "internal error",
-			}, "", "", "", nil
+			}, "", "", "", nil, nil, 0
 		}
 
 		reviewers = append(reviewers, userID)
@@ -382,8 +1563,8 @@ func (pr *PullRequestsRepository) PullRequestReassign(ctx context.Context, pullR
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", "", "", nil
+		}, "", "", "", nil, nil, 0
 	}
 
-	return nil, pullRequestName, authorID, status, reviewers
+	return nil, pullRequestName, authorID, status, reviewers, fallbackReviewers, version
 }
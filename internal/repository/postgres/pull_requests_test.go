@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestCheckVersion(t *testing.T) {
+	version := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		name     string
+		expected *int
+		current  int
+		wantErr  bool
+	}{
+		{"not checked", nil, 5, false},
+		{"same version", version(5), 5, false},
+		{"older version", version(4), 5, true},
+		{"newer version", version(6), 5, true},
+		{"weak tag", version(models.WeakExpectedVersion), 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errResp := checkVersion(tt.expected, tt.current)
+			if !tt.wantErr && errResp != nil {
+				t.Errorf("checkVersion() = %+v, want nil", errResp)
+			} else if tt.wantErr && (errResp == nil || errResp.Code != codes.ErrVersionMismatch) {
+				t.Errorf("checkVersion() = %+v, want %s", errResp, codes.ErrVersionMismatch)
+			}
+		})
+	}
+}
+
+func TestCheckReviewersEditable(t *testing.T) {
+	tests := []struct {
+		status   string
+		wantCode string // пусто - ревьюеров можно менять
+	}{
+		{models.StatusOpen, ""},
+		{models.StatusDraft, codes.ErrInvalidTransition},
+		{models.StatusClosed, codes.ErrInvalidTransition},
+		{models.StatusMerged, codes.ErrPRMerged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			errResp := checkReviewersEditable(tt.status)
+			if tt.wantCode == "" && errResp != nil {
+				t.Errorf("checkReviewersEditable(%q) = %+v, want nil", tt.status, errResp)
+			} else if tt.wantCode != "" && (errResp == nil || errResp.Code != tt.wantCode) {
+				t.Errorf("checkReviewersEditable(%q) = %+v, want %s", tt.status, errResp, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Общий интерфейс *sql.DB и *sql.Tx, чтобы вспомогательные запросы можно было выполнять как в транзакции, так и без нее
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
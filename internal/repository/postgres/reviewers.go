@@ -0,0 +1,843 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
+)
+
+var (
+	errNoCandidate      = errors.New("no available candidates")
+	errCapacityExceeded = errors.New("all candidates reached max open reviews")
+	errNoLeadCandidate  = errors.New("no available lead")
+)
+
+// Условие "пользователь u сейчас не отсутствует" для запросов кандидатов
+const notAbsentCondition = `NOT EXISTS (
+		SELECT 1 FROM user_absences ua
+		WHERE ua.user_id = u.user_id AND ua.starts_at <= NOW() AND ua.ends_at > NOW()
+	)`
+
+// Получение кандидатов в ревьюеры: активные (и не отсутствующие сейчас) участники неархивной команды
+// (основные и дополнительные, кроме наблюдателей), кроме автора и уже назначенных ревьюеров пулл реквеста.
+// Вместе с кандидатом возвращается количество OPEN пулл реквестов, которые он уже ревьюит, его лимит
+// (свой или основной команды, nil - без лимита) и количество тегов пулл реквеста, которые у него есть.
+func getReviewerCandidates(ctx context.Context, tx *sql.Tx, teamName, authorID, pullRequestID string) ([]models.ReviewerCandidate, error) {
+	queryCandidates := `
+	SELECT
+		u.user_id,
+		m.team_name,
+		m.role,
+		COUNT(pr.pull_request_id) AS open_reviews,
+		COALESCE(u.max_open_reviews, t.max_open_reviews) AS max_open_reviews,
+		(
+			SELECT COUNT(*) FROM user_tags ut
+			JOIN pr_tags pt ON pt.tag = ut.tag
+			WHERE ut.user_id = u.user_id AND pt.pull_request_id = $3
+		) AS matched_tags
+	FROM team_memberships m
+	JOIN teams mt ON mt.team_name = m.team_name
+	JOIN users u ON u.user_id = m.user_id
+	JOIN teams t ON t.team_name = u.team_name
+	LEFT JOIN pr_reviewers r ON r.user_id = u.user_id
+	LEFT JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id AND pr.status = 'OPEN'
+	WHERE
+		m.team_name = $1 AND
+		m.left_at IS NULL AND
+		m.role <> 'observer' AND
+		mt.archived_at IS NULL AND
+		u.is_active = true AND
+		u.user_id <> $2 AND
+		u.user_id NOT IN (SELECT user_id FROM pr_reviewers WHERE pull_request_id = $3) AND
+		` + notAbsentCondition + `
+	GROUP BY u.user_id, m.team_name, m.role, t.max_open_reviews
+	ORDER BY u.user_id;
+	`
+	rows, err := tx.QueryContext(ctx, queryCandidates, teamName, authorID, pullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]models.ReviewerCandidate, 0)
+	for rows.Next() {
+		var (
+			candidate      models.ReviewerCandidate
+			maxOpenReviews sql.NullInt64
+		)
+
+		if err := rows.Scan(&candidate.UserID, &candidate.TeamName, &candidate.Role, &candidate.OpenReviews, &maxOpenReviews, &candidate.MatchedTags); err != nil {
+			return nil, err
+		}
+		if maxOpenReviews.Valid {
+			limit := int(maxOpenReviews.Int64)
+			candidate.MaxOpenReviews = &limit
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// Получение запасных команд (в порядке приоритета), из которых берутся ревьюеры, если команде их не хватает
+func getFallbackTeams(ctx context.Context, q querier, teamName string) ([]string, error) {
+	queryFallbackTeams := "SELECT fallback_team_name FROM team_fallbacks WHERE team_name = $1 ORDER BY position;"
+	rows, err := q.QueryContext(ctx, queryFallbackTeams, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fallbackTeams := make([]string, 0)
+	for rows.Next() {
+		var fallbackTeam string
+
+		if err := rows.Scan(&fallbackTeam); err != nil {
+			return nil, err
+		}
+
+		fallbackTeams = append(fallbackTeams, fallbackTeam)
+	}
+
+	return fallbackTeams, rows.Err()
+}
+
+// Выбор needed ревьюеров: сначала из команды, затем (если кандидатов не хватило) из запасных команд по порядку.
+// Кандидаты, достигшие лимита OPEN ревью, не выбираются. Вторым значением возвращаются ревьюеры, выбранные
+// из запасных команд, третьим - не хватило ли ревьюеров из-за лимитов.
+func selectReviewersWithFallback(ctx context.Context, tx *sql.Tx, teamName, authorID, pullRequestID string, needed int, selectReviewers repository.ReviewerSelectFunc) ([]string, []string, bool, error) {
+	reviewers := make([]string, 0)
+	fallbackReviewers := make([]string, 0)
+	if needed <= 0 {
+		return reviewers, fallbackReviewers, false, nil
+	}
+
+	fallbackTeams, err := getFallbackTeams(ctx, tx, teamName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	capacityBlocked := false
+	for i, poolTeam := range append([]string{teamName}, fallbackTeams...) {
+		if len(reviewers) >= needed {
+			break
+		}
+
+		candidates, err := getReviewerCandidates(ctx, tx, poolTeam, authorID, pullRequestID)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		available, full := withinCapacity(excludeCandidates(candidates, reviewers))
+		capacityBlocked = capacityBlocked || full
+
+		selected := selectReviewers(available, needed-len(reviewers))
+		reviewers = append(reviewers, selected...)
+		if i > 0 {
+			fallbackReviewers = append(fallbackReviewers, selected...)
+		}
+	}
+
+	return reviewers, fallbackReviewers, capacityBlocked && len(reviewers) < needed, nil
+}
+
+// Кандидаты, не достигшие своего лимита OPEN ревью. Вторым значением возвращается, был ли кто-то исключен из-за лимита
+func withinCapacity(candidates []models.ReviewerCandidate) ([]models.ReviewerCandidate, bool) {
+	available := make([]models.ReviewerCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.MaxOpenReviews == nil || candidate.OpenReviews < *candidate.MaxOpenReviews {
+			available = append(available, candidate)
+		}
+	}
+
+	return available, len(available) < len(candidates)
+}
+
+func excludeCandidates(candidates []models.ReviewerCandidate, userIDs []string) []models.ReviewerCandidate {
+	result := make([]models.ReviewerCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !slices.Contains(userIDs, candidate.UserID) {
+			result = append(result, candidate)
+		}
+	}
+
+	return result
+}
+
+// Выбор обязательных ревьюеров по правилам владения кодом: пользователи-владельцы назначаются напрямую
+// (если активны, состоят в неархивной команде, не отсутствуют, не достигли лимита и не являются автором), из каждой команды-владельца
+// выбирается один ревьюер, если среди уже выбранных нет участника этой команды (основного или дополнительного).
+// Для команд из LeadTeamNames подходят только лиды команды
+func selectOwnerReviewers(ctx context.Context, tx *sql.Tx, authorID, pullRequestID string, required models.RequiredReviewers, selectReviewers repository.ReviewerSelectFunc) ([]string, error) {
+	ownerReviewers := make([]string, 0)
+	coveredTeams := make(map[string]struct{})
+	coveredLeadTeams := make(map[string]struct{})
+
+	if len(required.UserIDs) > 0 {
+		queryOwners := `
+		SELECT
+			u.user_id,
+			ARRAY(SELECT m.team_name FROM team_memberships m WHERE m.user_id = u.user_id AND m.left_at IS NULL) AS teams,
+			ARRAY(SELECT m.team_name FROM team_memberships m WHERE m.user_id = u.user_id AND m.left_at IS NULL AND m.role = 'lead') AS lead_teams
+		FROM users u
+		JOIN teams t ON t.team_name = u.team_name
+		WHERE
+			u.user_id = ANY($1::varchar[]) AND
+			t.archived_at IS NULL AND
+			u.is_active = true AND
+			u.user_id <> $2 AND
+			` + notAbsentCondition + ` AND
+			(
+				COALESCE(u.max_open_reviews, t.max_open_reviews) IS NULL OR
+				COALESCE(u.max_open_reviews, t.max_open_reviews) > (
+					SELECT COUNT(*) FROM pr_reviewers r
+					JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+					WHERE r.user_id = u.user_id AND pr.status = 'OPEN'
+				)
+			)
+		ORDER BY u.user_id;
+		`
+		rows, err := tx.QueryContext(ctx, queryOwners, pq.Array(required.UserIDs), authorID)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var (
+				userID        string
+				teamNames     []string
+				leadTeamNames []string
+			)
+
+			if err := rows.Scan(&userID, pq.Array(&teamNames), pq.Array(&leadTeamNames)); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			ownerReviewers = append(ownerReviewers, userID)
+			for _, teamName := range teamNames {
+				coveredTeams[teamName] = struct{}{}
+			}
+			for _, teamName := range leadTeamNames {
+				coveredLeadTeams[teamName] = struct{}{}
+			}
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, teamName := range required.TeamNames {
+		leadRequired := slices.Contains(required.LeadTeamNames, teamName)
+
+		covered := coveredTeams
+		if leadRequired {
+			covered = coveredLeadTeams
+		}
+		if _, ok := covered[teamName]; ok {
+			continue
+		}
+
+		candidates, err := getReviewerCandidates(ctx, tx, teamName, authorID, pullRequestID)
+		if err != nil {
+			return nil, err
+		}
+
+		if leadRequired {
+			candidates = slices.DeleteFunc(candidates, func(candidate models.ReviewerCandidate) bool {
+				return candidate.Role != models.RoleLead
+			})
+		}
+
+		available, _ := withinCapacity(excludeCandidates(candidates, ownerReviewers))
+		selected := selectReviewers(available, 1)
+		if leadRequired && len(selected) == 0 {
+			return nil, fmt.Errorf("%w in code owner team %s", errNoLeadCandidate, teamName)
+		}
+		ownerReviewers = append(ownerReviewers, selected...)
+		coveredTeams[teamName] = struct{}{}
+	}
+
+	return ownerReviewers, nil
+}
+
+// Назначение ревьюеров пулл реквесту: сначала обязательные по правилам владения кодом, затем на оставшиеся
+// места (до reviewers_count) из команды автора и запасных команд. Возвращаются все назначенные ревьюеры,
+// обязательные, взятые из запасных команд, и не хватило ли ревьюеров из-за лимитов.
+func assignReviewers(ctx context.Context, tx *sql.Tx, pullRequestID, authorID, teamName string, reviewersCount int, required models.RequiredReviewers, selectReviewers repository.ReviewerSelectFunc) ([]string, []string, []string, bool, error) {
+	ownerReviewers, err := selectOwnerReviewers(ctx, tx, authorID, pullRequestID, required, selectReviewers)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	queryInsertReviewers := "INSERT INTO pr_reviewers (pull_request_id, user_id) SELECT $1, unnest($2::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryInsertReviewers, pullRequestID, pq.Array(ownerReviewers)); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	teamReviewers, fallbackReviewers, capacityBlocked, err := selectReviewersWithFallback(ctx, tx, teamName, authorID, pullRequestID, reviewersCount-len(ownerReviewers), selectReviewers)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, queryInsertReviewers, pullRequestID, pq.Array(teamReviewers)); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	return slices.Concat(ownerReviewers, teamReviewers), ownerReviewers, fallbackReviewers, capacityBlocked, nil
+}
+
+// Замена ревьюера на пулл реквесте один к одному: old_user_id снимается, а вместо него назначается один новый ревьюер
+// (с учетом запасных команд). Если кандидатов нет, возвращается errNoCandidate
+// (или errCapacityExceeded, если все кандидаты достигли лимита) и ничего не изменяется.
+func replaceReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, oldUserID, teamName, authorID string, selectReviewers repository.ReviewerSelectFunc) ([]string, []string, error) {
+	newReviewers, fallbackReviewers, capacityBlocked, err := selectReviewersWithFallback(ctx, tx, teamName, authorID, pullRequestID, 1, selectReviewers)
+	if err != nil {
+		return nil, nil, err
+	} else if len(newReviewers) == 0 && capacityBlocked {
+		return nil, nil, errCapacityExceeded
+	} else if len(newReviewers) == 0 {
+		return nil, nil, errNoCandidate
+	}
+
+	if err := removeReviewer(ctx, tx, pullRequestID, oldUserID); err != nil {
+		return nil, nil, err
+	}
+
+	queryInsertReviewers := "INSERT INTO pr_reviewers (pull_request_id, user_id) SELECT $1, unnest($2::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryInsertReviewers, pullRequestID, pq.Array(newReviewers)); err != nil {
+		return nil, nil, err
+	}
+
+	return newReviewers, fallbackReviewers, nil
+}
+
+// Снятие ревьюеров, которые больше не подходят пулл реквесту: неактивных и тех, кто не состоит
+// ни в команде автора, ни в ее запасных командах и не является владельцем кода по required.
+// Возвращаются оставшиеся ревьюеры, все их текущие команды, команды, в которых они лиды, и снятые ревьюеры.
+func dropInvalidReviewers(ctx context.Context, tx *sql.Tx, pullRequestID, teamName string, required models.RequiredReviewers) ([]string, []string, []string, []string, error) {
+	fallbackTeams, err := getFallbackTeams(ctx, tx, teamName)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	validTeams := slices.Concat([]string{teamName}, fallbackTeams, required.TeamNames)
+
+	queryReviewers := `
+	SELECT
+		r.user_id,
+		ARRAY(SELECT m.team_name FROM team_memberships m WHERE m.user_id = r.user_id AND m.left_at IS NULL) AS teams,
+		ARRAY(SELECT m.team_name FROM team_memberships m WHERE m.user_id = r.user_id AND m.left_at IS NULL AND m.role = 'lead') AS lead_teams,
+		u.is_active
+	FROM pr_reviewers r
+	JOIN users u ON u.user_id = r.user_id
+	WHERE r.pull_request_id = $1
+	ORDER BY r.pr_reviewers_id;
+	`
+	rows, err := tx.QueryContext(ctx, queryReviewers, pullRequestID)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	kept := make([]string, 0)
+	keptTeams := make([]string, 0)
+	keptLeadTeams := make([]string, 0)
+	removed := make([]string, 0)
+	for rows.Next() {
+		var (
+			userID            string
+			reviewerTeams     []string
+			reviewerLeadTeams []string
+			isActive          bool
+		)
+
+		if err := rows.Scan(&userID, pq.Array(&reviewerTeams), pq.Array(&reviewerLeadTeams), &isActive); err != nil {
+			rows.Close()
+			return nil, nil, nil, nil, err
+		}
+
+		inValidTeam := slices.ContainsFunc(reviewerTeams, func(reviewerTeam string) bool {
+			return slices.Contains(validTeams, reviewerTeam)
+		})
+		if isActive && (inValidTeam || slices.Contains(required.UserIDs, userID)) {
+			kept = append(kept, userID)
+			keptTeams = append(keptTeams, reviewerTeams...)
+			keptLeadTeams = append(keptLeadTeams, reviewerLeadTeams...)
+		} else {
+			removed = append(removed, userID)
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	queryDeleteReviewers := "DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND user_id = ANY($2::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryDeleteReviewers, pullRequestID, pq.Array(removed)); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return kept, keptTeams, keptLeadTeams, removed, nil
+}
+
+// Проверка пользователя, которого назначают ревьюером вручную (/pullRequest/addReviewer и reassign с new_user_id):
+// он должен существовать (NOT_FOUND), быть активным, не быть автором, состоять (с любой ролью) в неархивной команде автора
+// или в одной из ее запасных команд и не отсутствовать сейчас (REVIEWER_NOT_ELIGIBLE), а также не достигнуть
+// лимита OPEN ревью (CAPACITY_EXCEEDED). Вторым значением возвращается, назначен ли он уже ревьюером
+// (тогда остальные проверки не выполняются)
+func checkManualReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, authorID, authorsTeam, userID string) (*models.ErrorResponse, bool, error) {
+	var (
+		alreadyReviewer bool
+		isActive        bool
+		teamNames       []string
+		available       bool
+		openReviews     int
+		maxOpenReviews  sql.NullInt64
+	)
+
+	queryCheckUser := `
+	SELECT
+		EXISTS(
+			SELECT 1 FROM pr_reviewers r
+			WHERE r.pull_request_id = $2 AND r.user_id = u.user_id
+		) AS already_reviewer,
+		u.is_active,
+		ARRAY(
+			SELECT m.team_name FROM team_memberships m
+			JOIN teams mt ON mt.team_name = m.team_name
+			WHERE m.user_id = u.user_id AND m.left_at IS NULL AND mt.archived_at IS NULL
+		) AS teams,
+		` + notAbsentCondition + ` AS available,
+		(
+			SELECT COUNT(*) FROM pr_reviewers r
+			JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+			WHERE r.user_id = u.user_id AND pr.status = 'OPEN'
+		) AS open_reviews,
+		COALESCE(u.max_open_reviews, t.max_open_reviews) AS max_open_reviews
+	FROM users u
+	JOIN teams t ON t.team_name = u.team_name
+	WHERE u.user_id = $1;
+	`
+	err := tx.QueryRowContext(ctx, queryCheckUser, userID, pullRequestID).Scan(
+		&alreadyReviewer, &isActive, pq.Array(&teamNames), &available, &openReviews, &maxOpenReviews)
+	if err == sql.ErrNoRows {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if alreadyReviewer {
+		return nil, true, nil
+	}
+
+	fallbackTeams, err := getFallbackTeams(ctx, tx, authorsTeam)
+	if err != nil {
+		return nil, false, err
+	}
+
+	allowedTeams := append([]string{authorsTeam}, fallbackTeams...)
+	inAllowedTeam := slices.ContainsFunc(teamNames, func(teamName string) bool {
+		return slices.Contains(allowedTeams, teamName)
+	})
+
+	var message string
+	switch {
+	case userID == authorID:
+		message = "author can not review own pull request"
+	case !isActive:
+		message = "user is not active"
+	case !inAllowedTeam:
+		message = "user is not a member of the author's team or its fallback teams"
+	case !available:
+		message = "user is absent"
+	}
+	if message != "" {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotEligible,
+			Message: message,
+		}, false, nil
+	}
+
+	if maxOpenReviews.Valid && int64(openReviews) >= maxOpenReviews.Int64 {
+		return &models.ErrorResponse{
+			Code:    codes.ErrCapacityExceeded,
+			Message: "user reached max open reviews",
+		}, false, nil
+	}
+
+	return nil, false, nil
+}
+
+func removeReviewer(ctx context.Context, tx *sql.Tx, pullRequestID, userID string) error {
+	queryDeleteReviewer := "DELETE FROM pr_reviewers WHERE user_id = $1 AND pull_request_id = $2;"
+	_, err := tx.ExecContext(ctx, queryDeleteReviewer, userID, pullRequestID)
+	return err
+}
+
+// Получение всех активных (и не отсутствующих сейчас) участников неархивной команды (основных и дополнительных,
+// кроме наблюдателей) вместе с количеством OPEN пулл реквестов, которые они ревьюят, их лимитом и тегами
+func getTeamCandidates(ctx context.Context, tx *sql.Tx, teamName string) ([]models.ReviewerCandidate, error) {
+	queryCandidates := `
+	SELECT
+		u.user_id,
+		m.team_name,
+		m.role,
+		COUNT(pr.pull_request_id) AS open_reviews,
+		COALESCE(u.max_open_reviews, t.max_open_reviews) AS max_open_reviews,
+		ARRAY(SELECT tag FROM user_tags ut WHERE ut.user_id = u.user_id ORDER BY tag) AS tags
+	FROM team_memberships m
+	JOIN teams mt ON mt.team_name = m.team_name
+	JOIN users u ON u.user_id = m.user_id
+	JOIN teams t ON t.team_name = u.team_name
+	LEFT JOIN pr_reviewers r ON r.user_id = u.user_id
+	LEFT JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id AND pr.status = 'OPEN'
+	WHERE m.team_name = $1 AND m.left_at IS NULL AND m.role <> 'observer' AND mt.archived_at IS NULL AND u.is_active = true AND ` + notAbsentCondition + `
+	GROUP BY u.user_id, m.team_name, m.role, t.max_open_reviews
+	ORDER BY u.user_id;
+	`
+	rows, err := tx.QueryContext(ctx, queryCandidates, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]models.ReviewerCandidate, 0)
+	for rows.Next() {
+		var (
+			candidate      models.ReviewerCandidate
+			maxOpenReviews sql.NullInt64
+		)
+
+		if err := rows.Scan(&candidate.UserID, &candidate.TeamName, &candidate.Role, &candidate.OpenReviews, &maxOpenReviews, pq.Array(&candidate.Tags)); err != nil {
+			return nil, err
+		}
+		if maxOpenReviews.Valid {
+			limit := int(maxOpenReviews.Int64)
+			candidate.MaxOpenReviews = &limit
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// Переназначение всех OPEN пулл реквестов, которые ревьюят пользователи, по тем же правилам, что и PullRequestReassign
+// (сначала кандидаты из команды автора пулл реквеста, затем из ее запасных команд).
+// Если кандидатов нет, пользователи все равно снимаются с пулл реквеста, а пулл реквест попадает в no_candidate
+// (или в capacity_exceeded, если все кандидаты достигли лимита OPEN ревью).
+// С onlyIneligible пользователи снимаются только с тех пулл реквестов, ревьюить которые они больше не могут
+// (неактивны или не состоят в неархивной команде автора или в одной из ее запасных команд), например после перевода в другую команду.
+// Количество запросов не зависит от количества пользователей и пулл реквестов (только от количества команд их авторов):
+// кандидаты выбираются в памяти, а удаление и добавление ревьюеров делается одним запросом.
+func releaseOpenReviews(ctx context.Context, tx *sql.Tx, userIDs []string, onlyIneligible bool, selectReviewers repository.ReviewerSelectFunc) (*models.ReassignmentReport, error) {
+	type openReview struct {
+		pullRequestID  string
+		authorID       string
+		authorsTeam    string
+		reviewersCount int
+		oldReviewers   []string
+		reviewers      map[string]struct{}
+		tags           []string
+	}
+
+	report := &models.ReassignmentReport{
+		Reassigned:       make([]models.ReviewReassignment, 0),
+		NoCandidate:      make([]string, 0),
+		CapacityExceeded: make([]string, 0),
+	}
+
+	// OPEN пулл реквесты, которые ревьюят пользователи (строки пулл реквестов блокируются)
+
+	queryOpenReviews := `
+	SELECT r.pull_request_id, r.user_id, pr.author_id, a.team_name, t.reviewers_count
+	FROM pr_reviewers r
+	JOIN pull_requests pr ON pr.pull_request_id = r.pull_request_id
+	JOIN users a ON a.user_id = pr.author_id
+	JOIN teams t ON t.team_name = a.team_name
+	JOIN users u ON u.user_id = r.user_id
+	WHERE
+		r.user_id = ANY($1::varchar[]) AND
+		pr.status = 'OPEN' AND
+		(
+			NOT $2 OR
+			NOT u.is_active OR
+			NOT EXISTS (
+				SELECT 1 FROM team_memberships m
+				JOIN teams mt ON mt.team_name = m.team_name
+				WHERE
+					m.user_id = r.user_id AND
+					m.left_at IS NULL AND
+					mt.archived_at IS NULL AND
+					(
+						m.team_name = a.team_name OR
+						m.team_name IN (SELECT f.fallback_team_name FROM team_fallbacks f WHERE f.team_name = a.team_name)
+					)
+			)
+		)
+	ORDER BY r.pull_request_id, r.user_id
+	FOR UPDATE OF pr;
+	`
+	rows, err := tx.QueryContext(ctx, queryOpenReviews, pq.Array(userIDs), onlyIneligible)
+	if err != nil {
+		return nil, err
+	}
+
+	openReviews := make([]*openReview, 0)
+	openReviewsMap := make(map[string]*openReview)
+	for rows.Next() {
+		var (
+			review    openReview
+			oldUserID string
+		)
+
+		if err := rows.Scan(&review.pullRequestID, &oldUserID, &review.authorID, &review.authorsTeam, &review.reviewersCount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if existing, ok := openReviewsMap[review.pullRequestID]; ok {
+			existing.oldReviewers = append(existing.oldReviewers, oldUserID)
+			continue
+		}
+
+		review.oldReviewers = []string{oldUserID}
+		review.reviewers = make(map[string]struct{})
+		openReviews = append(openReviews, &review)
+		openReviewsMap[review.pullRequestID] = &review
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(openReviews) == 0 {
+		return report, nil
+	}
+
+	pullRequestIDs := make([]string, len(openReviews))
+	for i, review := range openReviews {
+		pullRequestIDs[i] = review.pullRequestID
+	}
+
+	// Ревьюеры этих пулл реквестов, которые остаются на них
+
+	queryReviewers := "SELECT pull_request_id, user_id FROM pr_reviewers WHERE pull_request_id = ANY($1::varchar[]);"
+	rows, err = tx.QueryContext(ctx, queryReviewers, pq.Array(pullRequestIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var pullRequestID, userID string
+
+		if err := rows.Scan(&pullRequestID, &userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		review := openReviewsMap[pullRequestID]
+		if !slices.Contains(review.oldReviewers, userID) {
+			review.reviewers[userID] = struct{}{}
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Теги этих пулл реквестов
+
+	queryTags := "SELECT pull_request_id, tag FROM pr_tags WHERE pull_request_id = ANY($1::varchar[]);"
+	rows, err = tx.QueryContext(ctx, queryTags, pq.Array(pullRequestIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var pullRequestID, tag string
+
+		if err := rows.Scan(&pullRequestID, &tag); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		openReviewsMap[pullRequestID].tags = append(openReviewsMap[pullRequestID].tags, tag)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Кандидаты для каждой команды авторов: активные участники команды и ее запасных команд
+	// (деактивированные пользователи к этому моменту уже неактивны)
+
+	poolsByTeam := make(map[string][][]models.ReviewerCandidate)
+	for _, review := range openReviews {
+		if _, ok := poolsByTeam[review.authorsTeam]; ok {
+			continue
+		}
+
+		fallbackTeams, err := getFallbackTeams(ctx, tx, review.authorsTeam)
+		if err != nil {
+			return nil, err
+		}
+
+		pools := make([][]models.ReviewerCandidate, 0, len(fallbackTeams)+1)
+		for _, poolTeam := range append([]string{review.authorsTeam}, fallbackTeams...) {
+			candidates, err := getTeamCandidates(ctx, tx, poolTeam)
+			if err != nil {
+				return nil, err
+			}
+
+			pools = append(pools, candidates)
+		}
+
+		poolsByTeam[review.authorsTeam] = pools
+	}
+
+	// Выбор новых ревьюеров для каждого пулл реквеста
+
+	oldPullRequestIDs := make([]string, 0)
+	oldUserIDs := make([]string, 0)
+	newPullRequestIDs := make([]string, 0)
+	newUserIDs := make([]string, 0)
+	events := make([]models.PullRequestEvent, 0)
+	for _, review := range openReviews {
+		for _, userID := range review.oldReviewers {
+			oldPullRequestIDs = append(oldPullRequestIDs, review.pullRequestID)
+			oldUserIDs = append(oldUserIDs, userID)
+		}
+
+		needed := review.reviewersCount - len(review.reviewers)
+		if needed <= 0 {
+			report.Reassigned = append(report.Reassigned, models.ReviewReassignment{
+				PullRequestID: review.pullRequestID,
+				OldUserID:     review.oldReviewers[0],
+				OldReviewers:  review.oldReviewers,
+				NewReviewers:  make([]string, 0),
+			})
+			events = append(events, reviewerEvents(review.pullRequestID, EventUnassigned, review.oldReviewers)...)
+			continue
+		}
+
+		newReviewers := make([]string, 0)
+		fallbackReviewers := make([]string, 0)
+		capacityBlocked := false
+		for i, pool := range poolsByTeam[review.authorsTeam] {
+			if len(newReviewers) >= needed {
+				break
+			}
+
+			candidates := make([]models.ReviewerCandidate, 0, len(pool))
+			for _, candidate := range pool {
+				if _, ok := review.reviewers[candidate.UserID]; ok || candidate.UserID == review.authorID || slices.Contains(review.oldReviewers, candidate.UserID) || slices.Contains(newReviewers, candidate.UserID) {
+					continue
+				}
+
+				candidate.MatchedTags = 0
+				for _, tag := range review.tags {
+					if slices.Contains(candidate.Tags, tag) {
+						candidate.MatchedTags++
+					}
+				}
+
+				candidates = append(candidates, candidate)
+			}
+
+			available, full := withinCapacity(candidates)
+			capacityBlocked = capacityBlocked || full
+
+			selected := selectReviewers(available, needed-len(newReviewers))
+			newReviewers = append(newReviewers, selected...)
+			if i > 0 {
+				fallbackReviewers = append(fallbackReviewers, selected...)
+			}
+		}
+
+		if len(newReviewers) == 0 && capacityBlocked {
+			report.CapacityExceeded = append(report.CapacityExceeded, review.pullRequestID)
+			events = append(events, reviewerEvents(review.pullRequestID, EventUnassigned, review.oldReviewers)...)
+			continue
+		} else if len(newReviewers) == 0 {
+			report.NoCandidate = append(report.NoCandidate, review.pullRequestID)
+			events = append(events, reviewerEvents(review.pullRequestID, EventUnassigned, review.oldReviewers)...)
+			continue
+		}
+
+		// Учитываем новые назначения, чтобы нагрузка распределялась и между пулл реквестами
+		// (пользователь может быть кандидатом в пулах нескольких команд)
+
+		for _, userID := range newReviewers {
+			for _, pools := range poolsByTeam {
+				for _, pool := range pools {
+					for i := range pool {
+						if pool[i].UserID == userID {
+							pool[i].OpenReviews++
+						}
+					}
+				}
+			}
+
+			newPullRequestIDs = append(newPullRequestIDs, review.pullRequestID)
+			newUserIDs = append(newUserIDs, userID)
+		}
+
+		report.Reassigned = append(report.Reassigned, models.ReviewReassignment{
+			PullRequestID:     review.pullRequestID,
+			OldUserID:         review.oldReviewers[0],
+			OldReviewers:      review.oldReviewers,
+			NewReviewers:      newReviewers,
+			FallbackReviewers: fallbackReviewers,
+		})
+		events = append(events, replacementEvents(review.pullRequestID, review.oldReviewers, newReviewers)...)
+	}
+
+	// Снимаем пользователей и назначаем новых ревьюеров
+
+	queryDeleteReviewers := `
+	DELETE FROM pr_reviewers r
+	USING unnest($1::varchar[], $2::varchar[]) AS t(pr_id, u_id)
+	WHERE r.pull_request_id = t.pr_id AND r.user_id = t.u_id;
+	`
+	if _, err := tx.ExecContext(ctx, queryDeleteReviewers, pq.Array(oldPullRequestIDs), pq.Array(oldUserIDs)); err != nil {
+		return nil, err
+	}
+
+	queryInsertReviewers := `
+	INSERT INTO pr_reviewers (pull_request_id, user_id)
+	SELECT pr_id, u_id FROM unnest($1::varchar[], $2::varchar[]) AS t(pr_id, u_id);
+	`
+	if _, err := tx.ExecContext(ctx, queryInsertReviewers, pq.Array(newPullRequestIDs), pq.Array(newUserIDs)); err != nil {
+		return nil, err
+	}
+
+	// Новые версии пулл реквестов и запись в историю
+
+	queryBumpVersions := "UPDATE pull_requests SET version = version + 1 WHERE pull_request_id = ANY($1::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryBumpVersions, pq.Array(pullRequestIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := recordEvents(ctx, tx, events); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestWithinCapacity(t *testing.T) {
+	limit := func(v int) *int {
+		return &v
+	}
+	candidate := func(userID string, openReviews int, maxOpenReviews *int) models.ReviewerCandidate {
+		return models.ReviewerCandidate{UserID: userID, OpenReviews: openReviews, MaxOpenReviews: maxOpenReviews}
+	}
+
+	tests := []struct {
+		name       string
+		candidates []models.ReviewerCandidate
+		want       []string
+		wantFull   bool
+	}{
+		{
+			name:       "no candidates",
+			candidates: nil,
+			want:       []string{},
+		},
+		{
+			name:       "no limit",
+			candidates: []models.ReviewerCandidate{candidate("u1", 100, nil)},
+			want:       []string{"u1"},
+		},
+		{
+			name:       "under limit",
+			candidates: []models.ReviewerCandidate{candidate("u1", 2, limit(3))},
+			want:       []string{"u1"},
+		},
+		{
+			name:       "at limit",
+			candidates: []models.ReviewerCandidate{candidate("u1", 3, limit(3)), candidate("u2", 0, limit(3))},
+			want:       []string{"u2"},
+			wantFull:   true,
+		},
+		{
+			name:       "zero limit",
+			candidates: []models.ReviewerCandidate{candidate("u1", 0, limit(0)), candidate("u2", 0, nil)},
+			want:       []string{"u2"},
+			wantFull:   true,
+		},
+		{
+			name:       "all full",
+			candidates: []models.ReviewerCandidate{candidate("u1", 1, limit(1)), candidate("u2", 5, limit(2))},
+			want:       []string{},
+			wantFull:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available, full := withinCapacity(tt.candidates)
+
+			got := make([]string, 0, len(available))
+			for _, candidate := range available {
+				got = append(got, candidate.UserID)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) || full != tt.wantFull {
+				t.Errorf("withinCapacity() = %v, %v, want %v, %v", got, full, tt.want, tt.wantFull)
+			}
+		})
+	}
+}
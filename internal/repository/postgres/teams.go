@@ -3,12 +3,17 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/tousart/avitotest/internal/codes"
 	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
 	"github.com/tousart/avitotest/pkg"
 )
 
@@ -26,7 +31,7 @@ func NewTeamsRepository(addressToConnectToPSQL string) (*TeamsRepository, error)
 	return &TeamsRepository{db: db}, nil
 }
 
-func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *models.ErrorResponse {
+func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, *models.ReassignmentReport) {
 	// Начинаем транзакцию
 
 	tx, err := tr.db.BeginTx(ctx, nil)
@@ -35,25 +40,45 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}
+		}, nil
 	}
 
 	// Добавление команды
 
-	queryCreateTeam := "INSERT INTO teams (team_name) values ($1);"
-	_, err = tx.ExecContext(ctx, queryCreateTeam, team.TeamName)
+	queryCreateTeam := "INSERT INTO teams (team_name, reviewers_count, max_open_reviews, required_approvals) values ($1, $2, NULLIF($3, 0), $4);"
+	_, err = tx.ExecContext(ctx, queryCreateTeam, team.TeamName, team.ReviewersCount, team.MaxOpenReviews, *team.RequiredApprovals)
 	if err != nil {
 		tx.Rollback()
 		log.Printf("repository: postgres: TeamAdd: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrTeamExists,
 			Message: "team_name already exists",
-		}
+		}, nil
+	}
+
+	// Добавление запасных команд
+
+	err = setFallbackTeams(ctx, tx, team.TeamName, team.FallbackTeams)
+	if err == errTeamNotFound {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "fallback team not found",
+		}, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamAdd: setFallbackTeams: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
 	}
 
 	usersID := make([]string, len(team.Members))
+	roles := make([]string, len(team.Members))
 	for i, teamMember := range team.Members {
 		usersID[i] = teamMember.UserID
+		roles[i] = teamMember.Role
 	}
 
 	// Поиск существующих пользователей
@@ -65,7 +90,7 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}
+		}, nil
 	}
 
 	existsUsers := make([]string, 0)
@@ -78,7 +103,7 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 			return &models.ErrorResponse{
 				Code:    codes.ErrInternal,
 				Message: "internal error",
-			}
+			}, nil
 		}
 
 		existsUsers = append(existsUsers, userID)
@@ -86,14 +111,28 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 	}
 	rowsExists.Close()
 
-	// Обновление команды у пользователей, которые существуют
+	// В режиме reject существующие пользователи (они уже состоят в другой команде) не переводятся
 
-	queryUpdateUsersTeam := "UPDATE users SET team_name = $1 WHERE user_id IN (SELECT * FROM unnest($2::varchar[]));"
-	_, err = tx.ExecContext(ctx, queryUpdateUsersTeam, team.TeamName, pq.Array(existsUsers))
-	if err != nil {
+	if team.MemberConflict == models.MemberConflictReject && len(existsUsers) > 0 {
+		tx.Rollback()
+		slices.Sort(existsUsers)
 		return &models.ErrorResponse{
-			Code:    codes.ErrInternal,
-			Message: "internal error",
+			Code:    codes.ErrUserInOtherTeam,
+			Message: fmt.Sprintf("users already belong to another team: %s", strings.Join(existsUsers, ", ")),
+		}, nil
+	}
+
+	// Смена основной команды у пользователей, которые существуют (в режиме join они вступают в команду
+	// как дополнительные участники при записи в историю членства)
+
+	if team.MemberConflict != models.MemberConflictJoin {
+		if err := setPrimaryTeam(ctx, tx, existsUsers, team.TeamName); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamAdd: setPrimaryTeam: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil
 		}
 	}
 
@@ -122,7 +161,43 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 			return &models.ErrorResponse{
 				Code:    codes.ErrInternal,
 				Message: "internal error",
-			}
+			}, nil
+		}
+	}
+
+	// Запись в историю членства
+
+	if err := joinTeam(ctx, tx, usersID, team.TeamName); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamAdd: joinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	if err := setMemberRoles(ctx, tx, team.TeamName, usersID, roles); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamAdd: setMemberRoles: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	// Переведенные пользователи снимаются с OPEN пулл реквестов, которые они больше не могут ревьюить
+	// (как при /users/moveTeam)
+
+	var report *models.ReassignmentReport
+	if team.MemberConflict != models.MemberConflictJoin {
+		report, err = releaseOpenReviews(ctx, tx, existsUsers, true, selectReviewers)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamAdd: releaseOpenReviews: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil
 		}
 	}
 
@@ -133,57 +208,651 @@ func (tr *TeamsRepository) TeamAdd(ctx context.Context, team *models.Team) *mode
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
+		}, nil
+	}
+
+	return nil, report
+}
+
+func (tr *TeamsRepository) TeamGet(ctx context.Context, team *models.Team) (*models.ErrorResponse, *models.TeamSettings, []models.TeamMember, []models.Absence) {
+	// Проверка: существует ли команда (и получение ее настроек)
+
+	settings, err := getTeamSettings(ctx, tr.db, team.TeamName)
+	if err == errTeamNotFound {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}, nil, nil, nil
+	} else if err != nil {
+		log.Printf("repository: postgres: TeamGet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	// Получение текущих участников команды (основных и дополнительных)
+
+	queryGetMembers := `
+	SELECT u.user_id, u.username, u.is_active, u.team_name, m.role
+	FROM team_memberships m
+	JOIN users u ON u.user_id = m.user_id
+	WHERE m.team_name = $1 AND m.left_at IS NULL
+	ORDER BY u.user_id;
+	`
+	rows, err := tr.db.QueryContext(ctx, queryGetMembers, team.TeamName)
+	if err != nil {
+		log.Printf("repository: postgres: TeamGet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+	defer rows.Close()
+
+	members := make([]models.TeamMember, 0)
+	for rows.Next() {
+		var member models.TeamMember
+
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.PrimaryTeam, &member.Role); err != nil {
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil
 		}
+
+		members = append(members, member)
 	}
 
-	return nil
+	// Получение текущих и предстоящих отсутствий участников команды
+
+	queryGetAbsences := `
+	SELECT a.absence_id, a.user_id, a.starts_at, a.ends_at
+	FROM user_absences a
+	JOIN team_memberships m ON m.user_id = a.user_id
+	WHERE m.team_name = $1 AND m.left_at IS NULL AND a.ends_at > NOW()
+	ORDER BY a.starts_at, a.user_id;
+	`
+	absenceRows, err := tr.db.QueryContext(ctx, queryGetAbsences, team.TeamName)
+	if err != nil {
+		log.Printf("repository: postgres: TeamGet: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+	defer absenceRows.Close()
+
+	absences := make([]models.Absence, 0)
+	for absenceRows.Next() {
+		var absence models.Absence
+
+		if err := absenceRows.Scan(&absence.AbsenceID, &absence.UserID, &absence.StartsAt, &absence.EndsAt); err != nil {
+			log.Printf("repository: postgres: TeamGet: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil
+		}
+
+		absences = append(absences, absence)
+	}
+
+	return nil, settings, members, absences
 }
 
-func (tr *TeamsRepository) TeamGet(ctx context.Context, team *models.Team) (*models.ErrorResponse, []models.TeamMember) {
+func (tr *TeamsRepository) TeamSetSettings(ctx context.Context, settings *models.TeamSettings) (*models.ErrorResponse, *models.TeamSettings) {
+	// Начинаем транзакцию
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: TeamSetSettings: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	// Обновление количества ревьюеров (0 - оставить без изменений), лимита OPEN ревью (не передан - оставить без изменений, 0 - без лимита)
+	// и количества одобрений для merge (не передано - оставить без изменений)
+
+	querySetSettings := `
+	UPDATE teams SET
+		reviewers_count = COALESCE(NULLIF($1, 0), reviewers_count),
+		max_open_reviews = CASE WHEN $3::integer IS NULL THEN max_open_reviews ELSE NULLIF($3, 0) END,
+		required_approvals = COALESCE($4::integer, required_approvals)
+	WHERE team_name = $2;
+	`
+	result, err := tx.ExecContext(ctx, querySetSettings, settings.ReviewersCount, settings.TeamName, settings.MaxOpenReviews, settings.RequiredApprovals)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamSetSettings: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamSetSettings: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}, nil
+	}
+
+	// Замена запасных команд (если список не передан, он остается без изменений)
+
+	if settings.FallbackTeams != nil {
+		err = setFallbackTeams(ctx, tx, settings.TeamName, settings.FallbackTeams)
+		if err == errTeamNotFound {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrNotFound,
+				Message: "fallback team not found",
+			}, nil
+		} else if err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamSetSettings: setFallbackTeams: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil
+		}
+	}
+
+	// Получение итоговых настроек
+
+	updated, err := getTeamSettings(ctx, tx, settings.TeamName)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamSetSettings: getTeamSettings: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: TeamSetSettings: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	return nil, updated
+}
+
+func (tr *TeamsRepository) TeamDeactivate(ctx context.Context, deactivation *models.TeamDeactivation, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, []string, *models.ReassignmentReport) {
+	// Начинаем транзакцию
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: TeamDeactivate: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
 	// Проверка: существует ли команда
 
 	var exists bool
 	queryTeamExists := "SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1);"
-	err := tr.db.QueryRowContext(ctx, queryTeamExists, team.TeamName).Scan(&exists)
+	err = tx.QueryRowContext(ctx, queryTeamExists, deactivation.TeamName).Scan(&exists)
 	if err != nil {
-		log.Printf("repository: postgres: TeamGet: %v\n", err)
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamDeactivate: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil
+		}, nil, nil
 	} else if !exists {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
 			Message: "team not found",
-		}, nil
+		}, nil, nil
 	}
 
-	// Получение пользователей по названию команды
+	// Деактивация участников: переданных user_ids (основных или дополнительных участников),
+	// а если они не переданы - всех, для кого команда основная
+
+	queryDeactivate := `
+	UPDATE users u SET is_active = false
+	WHERE
+		(cardinality($2::varchar[]) = 0 AND u.team_name = $1) OR
+		(u.user_id = ANY($2::varchar[]) AND EXISTS (
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = u.user_id AND m.team_name = $1 AND m.left_at IS NULL
+		))
+	RETURNING u.user_id;
+	`
+	rows, err := tx.QueryContext(ctx, queryDeactivate, deactivation.TeamName, pq.Array(deactivation.UserIDs))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamDeactivate: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
 
-	queryGetMembers := "SELECT user_id, username, is_active FROM users WHERE team_name = $1;"
-	rows, err := tr.db.QueryContext(ctx, queryGetMembers, team.TeamName)
+	deactivated := make([]string, 0)
+	for rows.Next() {
+		var userID string
+
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamDeactivate: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil
+		}
+
+		deactivated = append(deactivated, userID)
+	}
+	rows.Close()
+
+	if len(deactivation.UserIDs) > 0 && len(deactivated) != len(deactivation.UserIDs) {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "some users are not members of the team",
+		}, nil, nil
+	}
+
+	// Переназначение OPEN пулл реквестов деактивированных пользователей
+
+	report, err := releaseOpenReviews(ctx, tx, deactivated, false, selectReviewers)
 	if err != nil {
-		log.Printf("repository: postgres: TeamGet: %v\n", err)
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamDeactivate: releaseOpenReviews: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, nil
+		}, nil, nil
 	}
-	defer rows.Close()
 
-	members := make([]models.TeamMember, 0)
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: TeamDeactivate: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil
+	}
+
+	return nil, deactivated, report
+}
+
+func (tr *TeamsRepository) TeamArchive(ctx context.Context, archive *models.TeamArchive, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, []string, *models.ReassignmentReport, *time.Time) {
+	// Начинаем транзакцию
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	// Проверка: существуют ли команда и команда, в которую переводятся участники, и не в архиве ли они (строки блокируются)
+
+	teamNames := []string{archive.TeamName}
+	if archive.MoveToTeam != "" {
+		teamNames = append(teamNames, archive.MoveToTeam)
+	}
+
+	queryCheckTeams := "SELECT team_name, archived_at IS NOT NULL FROM teams WHERE team_name = ANY($1::varchar[]) FOR UPDATE;"
+	rows, err := tx.QueryContext(ctx, queryCheckTeams, pq.Array(teamNames))
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	archived := make(map[string]bool)
 	for rows.Next() {
-		var member models.TeamMember
+		var (
+			teamName   string
+			isArchived bool
+		)
+
+		if err := rows.Scan(&teamName, &isArchived); err != nil {
+			rows.Close()
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamArchive: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil
+		}
+
+		archived[teamName] = isArchived
+	}
+	rows.Close()
+
+	for _, teamName := range teamNames {
+		isArchived, ok := archived[teamName]
+		if !ok {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrNotFound,
+				Message: fmt.Sprintf("team %s not found", teamName),
+			}, nil, nil, nil
+		} else if isArchived {
+			tx.Rollback()
+			return &models.ErrorResponse{
+				Code:    codes.ErrTeamArchived,
+				Message: fmt.Sprintf("team %s is archived", teamName),
+			}, nil, nil, nil
+		}
+	}
+
+	// Архивация (до переназначения, чтобы участники команды уже не выбирались кандидатами)
+
+	var archivedAt time.Time
+	queryArchive := "UPDATE teams SET archived_at = NOW() WHERE team_name = $1 RETURNING archived_at;"
+	if err := tx.QueryRowContext(ctx, queryArchive, archive.TeamName).Scan(&archivedAt); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	// Участники, для которых команда основная, переводятся в другую команду или деактивируются
+	// (деактивированные остаются в архивной команде для истории)
+
+	queryReleaseMembers := "UPDATE users SET is_active = false WHERE team_name = $1 RETURNING user_id;"
+	if archive.MoveToTeam != "" {
+		queryReleaseMembers = "SELECT user_id FROM users WHERE team_name = $1 FOR UPDATE;"
+	}
+
+	rows, err = tx.QueryContext(ctx, queryReleaseMembers, archive.TeamName)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
 
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+	members := make([]string, 0)
+	for rows.Next() {
+		var userID string
+
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamArchive: %v\n", err)
 			return &models.ErrorResponse{
 				Code:    codes.ErrInternal,
 				Message: "internal error",
-			}, nil
+			}, nil, nil, nil
 		}
 
-		members = append(members, member)
+		members = append(members, userID)
+	}
+	rows.Close()
+	slices.Sort(members)
+
+	if archive.MoveToTeam != "" {
+		if err := setPrimaryTeam(ctx, tx, members, archive.MoveToTeam); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamArchive: setPrimaryTeam: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil
+		}
+	}
+
+	// Дополнительные участники просто выходят из команды
+
+	queryLeaveArchived := `
+	UPDATE team_memberships m SET left_at = NOW()
+	FROM users u
+	WHERE u.user_id = m.user_id AND m.team_name = $1 AND m.left_at IS NULL AND u.team_name <> $1
+	RETURNING m.user_id;
+	`
+	rows, err = tx.QueryContext(ctx, queryLeaveArchived, archive.TeamName)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	leftMembers := make([]string, 0)
+	for rows.Next() {
+		var userID string
+
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			log.Printf("repository: postgres: TeamArchive: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, nil, nil
+		}
+
+		leftMembers = append(leftMembers, userID)
+	}
+	rows.Close()
+
+	// Переназначение OPEN пулл реквестов основных и дополнительных участников (кандидаты - из команд авторов
+	// и их запасных команд, архивная команда в них уже не участвует). Участники снимаются только с тех
+	// пулл реквестов, которые больше не могут ревьюить: деактивированные - со всех, переведенные
+	// и вышедшие из команды - с тех, где они не состоят в команде автора или в ее запасных командах
+
+	report, err := releaseOpenReviews(ctx, tx, slices.Concat(members, leftMembers), true, selectReviewers)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamArchive: releaseOpenReviews: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: TeamArchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, nil, nil
+	}
+
+	return nil, members, report, &archivedAt
+}
+
+func (tr *TeamsRepository) TeamUnarchive(ctx context.Context, team *models.Team) *models.ErrorResponse {
+	queryUnarchive := "UPDATE teams SET archived_at = NULL WHERE team_name = $1;"
+	result, err := tr.db.ExecContext(ctx, queryUnarchive, team.TeamName)
+	if err != nil {
+		log.Printf("repository: postgres: TeamUnarchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		log.Printf("repository: postgres: TeamUnarchive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}
+	}
+
+	return nil
+}
+
+func (tr *TeamsRepository) TeamUpdateMember(ctx context.Context, update *models.TeamMemberUpdate) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: TeamUpdateMember: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Проверка: существует ли команда и не в архиве ли она
+
+	var archived bool
+	queryCheckTeam := "SELECT archived_at IS NOT NULL FROM teams WHERE team_name = $1 FOR SHARE;"
+	err = tx.QueryRowContext(ctx, queryCheckTeam, update.TeamName).Scan(&archived)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamUpdateMember: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if archived {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrTeamArchived,
+			Message: "team is archived",
+		}
+	}
+
+	// Изменение роли (пользователь должен сейчас состоять в команде)
+
+	queryUpdateRole := "UPDATE team_memberships SET role = $3 WHERE user_id = $1 AND team_name = $2 AND left_at IS NULL;"
+	result, err := tx.ExecContext(ctx, queryUpdateRole, update.UserID, update.TeamName, update.Role)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamUpdateMember: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: TeamUpdateMember: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user is not a member of the team",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: TeamUpdateMember: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+var errTeamNotFound = errors.New("team not found")
+
+// Получение настроек команды (errTeamNotFound, если команды нет)
+func getTeamSettings(ctx context.Context, q querier, teamName string) (*models.TeamSettings, error) {
+	settings := &models.TeamSettings{TeamName: teamName}
+
+	var (
+		maxOpenReviews, requiredApprovals int
+		archivedAt                        sql.NullTime
+	)
+	querySettings := "SELECT reviewers_count, COALESCE(max_open_reviews, 0), required_approvals, archived_at FROM teams WHERE team_name = $1;"
+	err := q.QueryRowContext(ctx, querySettings, teamName).Scan(&settings.ReviewersCount, &maxOpenReviews, &requiredApprovals, &archivedAt)
+	if err == sql.ErrNoRows {
+		return nil, errTeamNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	settings.MaxOpenReviews = &maxOpenReviews
+	settings.RequiredApprovals = &requiredApprovals
+	if archivedAt.Valid {
+		settings.ArchivedAt = &archivedAt.Time
+	}
+
+	settings.FallbackTeams, err = getFallbackTeams(ctx, q, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Замена списка запасных команд (порядок в списке - приоритет). errTeamNotFound, если какой-то команды нет или она в архиве
+func setFallbackTeams(ctx context.Context, tx *sql.Tx, teamName string, fallbackTeams []string) error {
+	queryDeleteFallbacks := "DELETE FROM team_fallbacks WHERE team_name = $1;"
+	if _, err := tx.ExecContext(ctx, queryDeleteFallbacks, teamName); err != nil {
+		return err
+	}
+
+	if len(fallbackTeams) == 0 {
+		return nil
+	}
+
+	var existing int
+	queryCountTeams := "SELECT COUNT(*) FROM teams WHERE team_name = ANY($1::varchar[]) AND archived_at IS NULL;"
+	if err := tx.QueryRowContext(ctx, queryCountTeams, pq.Array(fallbackTeams)).Scan(&existing); err != nil {
+		return err
+	} else if existing != len(fallbackTeams) {
+		return errTeamNotFound
 	}
 
-	return nil, members
+	queryInsertFallbacks := `
+	INSERT INTO team_fallbacks (team_name, fallback_team_name, position)
+	SELECT $1, f_team, f_position
+	FROM unnest($2::varchar[]) WITH ORDINALITY AS t(f_team, f_position);
+	`
+	_, err := tx.ExecContext(ctx, queryInsertFallbacks, teamName, pq.Array(fallbackTeams))
+	return err
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/lib/pq"
 	"github.com/tousart/avitotest/internal/codes"
 	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
 	"github.com/tousart/avitotest/pkg"
 )
 
@@ -25,27 +27,673 @@ func NewUsersRepository(addressToConnectToPSQL string) (*UsersRepository, error)
 	return &UsersRepository{db: db}, nil
 }
 
-func (ur *UsersRepository) SetIsActive(ctx context.Context, user *models.User) (*models.ErrorResponse, string, string) {
+// Поля пользователя для GetUser и ListUsers (max_open_reviews - собственный лимит, NULL - лимит команды)
+const userColumns = `
+	u.user_id,
+	u.username,
+	u.team_name,
+	u.is_active,
+	u.max_open_reviews,
+	ARRAY(SELECT ut.tag FROM user_tags ut WHERE ut.user_id = u.user_id ORDER BY ut.tag) AS tags`
+
+func scanUser(scan func(dest ...any) error) (models.User, error) {
+	var (
+		user           models.User
+		maxOpenReviews sql.NullInt64
+	)
+
+	if err := scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive, &maxOpenReviews, pq.Array(&user.Tags)); err != nil {
+		return models.User{}, err
+	}
+	if maxOpenReviews.Valid {
+		value := int(maxOpenReviews.Int64)
+		user.MaxOpenReviews = &value
+	}
+
+	return user, nil
+}
+
+func (ur *UsersRepository) GetUser(ctx context.Context, userID string) (*models.ErrorResponse, *models.User) {
+	queryGetUser := "SELECT" + userColumns + " FROM users u WHERE u.user_id = $1;"
+	user, err := scanUser(ur.db.QueryRowContext(ctx, queryGetUser, userID).Scan)
+	if err == sql.ErrNoRows {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}, nil
+	} else if err != nil {
+		log.Printf("repository: postgres: GetUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	user.Memberships, err = getMemberships(ctx, ur.db, userID)
+	if err != nil {
+		log.Printf("repository: postgres: GetUser: getMemberships: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil
+	}
+
+	return nil, &user
+}
+
+func (ur *UsersRepository) CreateUser(ctx context.Context, user *models.User) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Проверка: существует ли команда и не в архиве ли она (строка блокируется от архивации до конца транзакции)
+
+	var archived bool
+	queryCheckTeam := "SELECT archived_at IS NOT NULL FROM teams WHERE team_name = $1 FOR SHARE;"
+	err = tx.QueryRowContext(ctx, queryCheckTeam, user.TeamName).Scan(&archived)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if archived {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrTeamArchived,
+			Message: "team is archived",
+		}
+	}
+
+	// Добавление пользователя (без собственного лимита - лимит команды)
+
+	queryInsertUser := `
+	INSERT INTO users (user_id, username, team_name, is_active, max_open_reviews)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id) DO NOTHING;
+	`
+	result, err := tx.ExecContext(ctx, queryInsertUser, user.UserID, user.Username, user.TeamName, user.IsActive, user.MaxOpenReviews)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrUserExists,
+			Message: "user_id already exists",
+		}
+	}
+
+	queryInsertTags := "INSERT INTO user_tags (user_id, tag) SELECT $1, unnest($2::varchar[]);"
+	if _, err := tx.ExecContext(ctx, queryInsertTags, user.UserID, pq.Array(user.Tags)); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Запись в историю членства
+
+	if err := joinTeam(ctx, tx, []string{user.UserID}, user.TeamName); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: CreateUser: joinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: CreateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (ur *UsersRepository) UpdateUser(ctx context.Context, update *models.UserUpdate) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: UpdateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Обновление переданных полей (не переданные остаются без изменений)
+
+	queryUpdateUser := `
+	UPDATE users SET
+		username = COALESCE($2::varchar, username),
+		max_open_reviews = CASE
+			WHEN $4 THEN NULL
+			WHEN $3::integer IS NULL THEN max_open_reviews
+			ELSE $3
+		END
+	WHERE user_id = $1;
+	`
+	result, err := tx.ExecContext(ctx, queryUpdateUser, update.UserID, update.Username, update.MaxOpenReviews, update.InheritMaxOpenReviews)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: UpdateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: UpdateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if affected == 0 {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}
+	}
+
+	// Теги заменяются целиком (если переданы)
+
+	if update.Tags != nil {
+		queryDeleteTags := "DELETE FROM user_tags WHERE user_id = $1;"
+		if _, err := tx.ExecContext(ctx, queryDeleteTags, update.UserID); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: UpdateUser: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+
+		queryInsertTags := "INSERT INTO user_tags (user_id, tag) SELECT $1, unnest($2::varchar[]);"
+		if _, err := tx.ExecContext(ctx, queryInsertTags, update.UserID, pq.Array(update.Tags)); err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: UpdateUser: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: UpdateUser: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+// Список пользователей по user_id (keyset-пагинация). Фильтр по команде учитывает и дополнительное членство.
+// Пользователи архивных команд показываются, только если команда указана в фильтре.
+// Третьим значением возвращается, есть ли следующая страница
+func (ur *UsersRepository) ListUsers(ctx context.Context, filter *models.UserFilter) (*models.ErrorResponse, []models.User, bool) {
+	queryListUsers := "SELECT" + userColumns + `
+	FROM users u
+	JOIN teams t ON t.team_name = u.team_name
+	WHERE
+		($1 = '' OR EXISTS (
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = u.user_id AND m.team_name = $1 AND m.left_at IS NULL
+		)) AND
+		($1 <> '' OR t.archived_at IS NULL) AND
+		($2::boolean IS NULL OR u.is_active = $2) AND
+		($3 = '' OR u.user_id > $3)
+	ORDER BY u.user_id
+	LIMIT $4;
+	`
+	rows, err := ur.db.QueryContext(ctx, queryListUsers, filter.TeamName, filter.IsActive, filter.After, filter.Limit+1)
+	if err != nil {
+		log.Printf("repository: postgres: ListUsers: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, false
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, filter.Limit+1)
+	for rows.Next() {
+		user, err := scanUser(rows.Scan)
+		if err != nil {
+			log.Printf("repository: postgres: ListUsers: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, nil, false
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("repository: postgres: ListUsers: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, nil, false
+	}
+
+	hasMore := len(users) > filter.Limit
+	if hasMore {
+		users = users[:filter.Limit]
+	}
+
+	return nil, users, hasMore
+}
+
+func (ur *UsersRepository) SetIsActive(ctx context.Context, user *models.User, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, string, string, *models.ReassignmentReport) {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: SetIsActive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", "", nil
+	}
+
 	var (
 		username string
 		teamName string
 	)
 
 	querySetIsActive := "UPDATE users SET is_active = $1 WHERE user_id = $2 RETURNING username, team_name;"
-	err := ur.db.QueryRowContext(ctx, querySetIsActive, user.IsActive, user.UserID).Scan(&username, &teamName)
+	err = tx.QueryRowContext(ctx, querySetIsActive, user.IsActive, user.UserID).Scan(&username, &teamName)
 	if err == sql.ErrNoRows {
+		tx.Rollback()
 		return &models.ErrorResponse{
 			Code:    codes.ErrNotFound,
 			Message: "user not found",
-		}, "", ""
+		}, "", "", nil
 	} else if err != nil {
+		tx.Rollback()
 		log.Printf("repository: postgres: SetIsActive: %v\n", err)
 		return &models.ErrorResponse{
 			Code:    codes.ErrInternal,
 			Message: "internal error",
-		}, "", ""
+		}, "", "", nil
 	}
-	return nil, username, teamName
+
+	// При деактивации пользователь снимается со всех OPEN пулл реквестов, где он ревьюер
+
+	var report *models.ReassignmentReport
+	if !user.IsActive {
+		report, err = releaseOpenReviews(ctx, tx, []string{user.UserID}, false, selectReviewers)
+		if err != nil {
+			tx.Rollback()
+			log.Printf("repository: postgres: SetIsActive: releaseOpenReviews: %v\n", err)
+			return &models.ErrorResponse{
+				Code:    codes.ErrInternal,
+				Message: "internal error",
+			}, "", "", nil
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: SetIsActive: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", "", nil
+	}
+
+	return nil, username, teamName, report
+}
+
+func (ur *UsersRepository) MoveTeam(ctx context.Context, user *models.User, selectReviewers repository.ReviewerSelectFunc) (*models.ErrorResponse, string, bool, []models.TeamMembership, *models.ReassignmentReport) {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: MoveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	// Проверка: существует ли команда и не в архиве ли она (строка блокируется от архивации до конца транзакции)
+
+	var archived bool
+	queryCheckTeam := "SELECT archived_at IS NOT NULL FROM teams WHERE team_name = $1 FOR SHARE;"
+	err = tx.QueryRowContext(ctx, queryCheckTeam, user.TeamName).Scan(&archived)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}, "", false, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: MoveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	} else if archived {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrTeamArchived,
+			Message: "team is archived",
+		}, "", false, nil, nil
+	}
+
+	// Смена основной команды пользователя
+
+	var (
+		username string
+		isActive bool
+	)
+
+	queryUserExists := "SELECT username, is_active FROM users WHERE user_id = $1 FOR UPDATE;"
+	err = tx.QueryRowContext(ctx, queryUserExists, user.UserID).Scan(&username, &isActive)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}, "", false, nil, nil
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: MoveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	if err := setPrimaryTeam(ctx, tx, []string{user.UserID}, user.TeamName); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: MoveTeam: setPrimaryTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	// Пользователь снимается с OPEN пулл реквестов, которые он больше не может ревьюить
+	// (не состоит в команде автора или в ее запасных командах), остальные ревью остаются за ним
+
+	report, err := releaseOpenReviews(ctx, tx, []string{user.UserID}, true, selectReviewers)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: MoveTeam: releaseOpenReviews: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	memberships, err := getMemberships(ctx, tx, user.UserID)
+	if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: MoveTeam: getMemberships: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: MoveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, "", false, nil, nil
+	}
+
+	return nil, username, isActive, memberships, report
+}
+
+func (ur *UsersRepository) JoinTeam(ctx context.Context, user *models.User) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: JoinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Проверка: существует ли команда и не в архиве ли она (строка блокируется от архивации до конца транзакции)
+
+	var archived bool
+	queryCheckTeam := "SELECT archived_at IS NOT NULL FROM teams WHERE team_name = $1 FOR SHARE;"
+	err = tx.QueryRowContext(ctx, queryCheckTeam, user.TeamName).Scan(&archived)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "team not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: JoinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if archived {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrTeamArchived,
+			Message: "team is archived",
+		}
+	}
+
+	// Проверка на существование пользователя
+
+	var exists bool
+	queryExists := "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1);"
+	if err := tx.QueryRowContext(ctx, queryExists, user.UserID).Scan(&exists); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: JoinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	} else if !exists {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}
+	}
+
+	// Вступление в команду (повторное вступление ничего не меняет)
+
+	if err := joinTeam(ctx, tx, []string{user.UserID}, user.TeamName); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: JoinTeam: joinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: JoinTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (ur *UsersRepository) LeaveTeam(ctx context.Context, user *models.User) *models.ErrorResponse {
+	// Начинаем транзакцию
+
+	tx, err := ur.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("repository: postgres: LeaveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Проверка: пользователь состоит в команде, и она не основная (основная меняется через MoveTeam)
+
+	var (
+		primaryTeam string
+		isMember    bool
+	)
+
+	queryCheckMember := `
+	SELECT
+		u.team_name,
+		EXISTS(
+			SELECT 1 FROM team_memberships m
+			WHERE m.user_id = u.user_id AND m.team_name = $2 AND m.left_at IS NULL
+		) AS is_member
+	FROM users u
+	WHERE u.user_id = $1
+	FOR UPDATE OF u;
+	`
+	err = tx.QueryRowContext(ctx, queryCheckMember, user.UserID, user.TeamName).Scan(&primaryTeam, &isMember)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}
+	} else if err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: LeaveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	if primaryTeam == user.TeamName {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrPrimaryTeam,
+			Message: "can not leave primary team, move user to another team instead",
+		}
+	}
+
+	if !isMember {
+		tx.Rollback()
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user is not a member of the team",
+		}
+	}
+
+	// Выход из команды (OPEN ревью остаются за пользователем)
+
+	if err := leaveTeam(ctx, tx, []string{user.UserID}, user.TeamName); err != nil {
+		tx.Rollback()
+		log.Printf("repository: postgres: LeaveTeam: leaveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	// Коммит
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("repository: postgres: LeaveTeam: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}
+	}
+
+	return nil
+}
+
+func (ur *UsersRepository) AddAbsence(ctx context.Context, absence *models.Absence) (*models.ErrorResponse, int) {
+	var exists bool
+	queryExists := "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1);"
+	err := ur.db.QueryRowContext(ctx, queryExists, absence.UserID).Scan(&exists)
+	if err != nil {
+		log.Printf("repository: postgres: AddAbsence: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, 0
+	} else if !exists {
+		return &models.ErrorResponse{
+			Code:    codes.ErrNotFound,
+			Message: "user not found",
+		}, 0
+	}
+
+	var absenceID int
+	queryInsertAbsence := "INSERT INTO user_absences (user_id, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING absence_id;"
+	err = ur.db.QueryRowContext(ctx, queryInsertAbsence, absence.UserID, absence.StartsAt, absence.EndsAt).Scan(&absenceID)
+	if err != nil {
+		log.Printf("repository: postgres: AddAbsence: %v\n", err)
+		return &models.ErrorResponse{
+			Code:    codes.ErrInternal,
+			Message: "internal error",
+		}, 0
+	}
+
+	return nil, absenceID
 }
 
 func (ur *UsersRepository) GetReview(ctx context.Context, userID string) (*models.ErrorResponse, []models.PullRequestShort) {
@@ -114,8 +762,10 @@ func (ur *UsersRepository) GetActivity(ctx context.Context) (*models.ErrorRespon
 		COUNT(CASE WHEN pr.status = 'MERGED' THEN 1 END) AS merged_pr, 
 		COUNT(CASE WHEN pr.status = 'OPEN' THEN 1 END) as open_pr 
 	FROM users u 
+	JOIN teams t USING(team_name)
 	JOIN pr_reviewers p USING(user_id) 
 	JOIN pull_requests pr USING(pull_request_id)
+	WHERE t.archived_at IS NULL
 	GROUP BY u.username, u.user_id
 	ORDER BY pull_requests DESC;
 	`
@@ -7,8 +7,28 @@ import (
 	"github.com/tousart/avitotest/internal/models"
 )
 
+// Функция выбора ревьюеров из кандидатов (передается из слоя usecase)
+type ReviewerSelectFunc func(candidates []models.ReviewerCandidate, count int) []string
+
+// Пулл реквест из списка вместе с временем создания и merge (форматируются в слое usecase)
+type PullRequestRow struct {
+	PullRequest models.PullRequest
+	CreatedAt   time.Time
+	MergedAt    *time.Time
+}
+
 type PullRequestsRepository interface {
-	PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, []string)
-	PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string)
-	PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID string) (*models.ErrorResponse, string, string, string, []string)
+	PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest, required models.RequiredReviewers, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, *time.Time, []string, []string, []string, int)
+	PullRequestGet(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string, []string, []models.Review, int)
+	PullRequestList(ctx context.Context, filter *models.PullRequestFilter) (*models.ErrorResponse, []PullRequestRow, bool)
+	PullRequestHistory(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, []models.PullRequestEvent)
+	PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, *time.Time, *time.Time, string, string, string, int)
+	PullRequestState(ctx context.Context, pullRequest *models.PullRequest) (*models.ErrorResponse, string, []string)
+	PullRequestReady(ctx context.Context, pullRequest *models.PullRequest, required models.RequiredReviewers, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, []string, []string)
+	PullRequestClose(ctx context.Context, pullRequest *models.PullRequest, fromStatus string) *models.ErrorResponse
+	PullRequestReopen(ctx context.Context, pullRequest *models.PullRequest, fromStatus string, required models.RequiredReviewers, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, []string, []string)
+	PullRequestAddReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse
+	PullRequestRemoveReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse
+	PullRequestReview(ctx context.Context, pullRequest *models.PullRequest, review *models.Review) *models.ErrorResponse
+	PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID, newUserID string, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, string, string, string, []string, []string, int)
 }
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/tousart/avitotest/internal/models"
 )
 
 type TeamsRepository interface {
-	TeamAdd(ctx context.Context, team *models.Team) *models.ErrorResponse
-	TeamGet(ctx context.Context, team *models.Team) (*models.ErrorResponse, []models.TeamMember)
+	TeamAdd(ctx context.Context, team *models.Team, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, *models.ReassignmentReport)
+	TeamGet(ctx context.Context, team *models.Team) (*models.ErrorResponse, *models.TeamSettings, []models.TeamMember, []models.Absence)
+	TeamSetSettings(ctx context.Context, settings *models.TeamSettings) (*models.ErrorResponse, *models.TeamSettings)
+	TeamDeactivate(ctx context.Context, deactivation *models.TeamDeactivation, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, []string, *models.ReassignmentReport)
+	TeamArchive(ctx context.Context, archive *models.TeamArchive, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, []string, *models.ReassignmentReport, *time.Time)
+	TeamUnarchive(ctx context.Context, team *models.Team) *models.ErrorResponse
+	TeamUpdateMember(ctx context.Context, update *models.TeamMemberUpdate) *models.ErrorResponse
 }
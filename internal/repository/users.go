@@ -7,7 +7,15 @@ import (
 )
 
 type UsersRepository interface {
-	SetIsActive(ctx context.Context, user *models.User) (*models.ErrorResponse, string, string)
+	GetUser(ctx context.Context, userID string) (*models.ErrorResponse, *models.User)
+	CreateUser(ctx context.Context, user *models.User) *models.ErrorResponse
+	UpdateUser(ctx context.Context, update *models.UserUpdate) *models.ErrorResponse
+	ListUsers(ctx context.Context, filter *models.UserFilter) (*models.ErrorResponse, []models.User, bool)
+	SetIsActive(ctx context.Context, user *models.User, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, string, string, *models.ReassignmentReport)
+	MoveTeam(ctx context.Context, user *models.User, selectReviewers ReviewerSelectFunc) (*models.ErrorResponse, string, bool, []models.TeamMembership, *models.ReassignmentReport)
+	JoinTeam(ctx context.Context, user *models.User) *models.ErrorResponse
+	LeaveTeam(ctx context.Context, user *models.User) *models.ErrorResponse
+	AddAbsence(ctx context.Context, absence *models.Absence) (*models.ErrorResponse, int)
 	GetReview(ctx context.Context, userID string) (*models.ErrorResponse, []models.PullRequestShort)
 	GetActivity(ctx context.Context) (*models.ErrorResponse, []models.UserActivity)
 }
@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+type CodeOwnersService interface {
+	CodeOwnersSet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse
+	CodeOwnersGet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse
+}
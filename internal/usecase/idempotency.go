@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+type IdempotencyService interface {
+	IdempotencyBegin(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse
+	IdempotencySave(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse
+	IdempotencyRelease(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse
+}
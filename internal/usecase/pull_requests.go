@@ -8,6 +8,15 @@ import (
 
 type PullRequestsService interface {
 	PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
+	PullRequestGet(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
+	PullRequestList(ctx context.Context, filter *models.PullRequestFilter, list *models.PullRequestList) *models.ErrorResponse
+	PullRequestHistory(ctx context.Context, history *models.PullRequestHistory) *models.ErrorResponse
 	PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
-	PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID string) *models.ErrorResponse
+	PullRequestReady(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
+	PullRequestClose(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
+	PullRequestReopen(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse
+	PullRequestAddReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse
+	PullRequestRemoveReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse
+	PullRequestReview(ctx context.Context, pullRequest *models.PullRequest, review *models.Review) *models.ErrorResponse
+	PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID, newUserID string) *models.ErrorResponse
 }
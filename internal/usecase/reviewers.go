@@ -0,0 +1,9 @@
+package usecase
+
+import "github.com/tousart/avitotest/internal/models"
+
+// Стратегия выбора ревьюеров из списка кандидатов
+type ReviewerSelector interface {
+	Name() string
+	Select(candidates []models.ReviewerCandidate, count int) []string
+}
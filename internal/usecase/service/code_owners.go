@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
+)
+
+type CodeOwnersService struct {
+	repo repository.CodeOwnersRepository
+}
+
+func NewCodeOwnersService(repo repository.CodeOwnersRepository) *CodeOwnersService {
+	return &CodeOwnersService{
+		repo: repo,
+	}
+}
+
+func (cs *CodeOwnersService) CodeOwnersSet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse {
+	err := cs.repo.CodeOwnersSet(ctx, codeOwners)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cs *CodeOwnersService) CodeOwnersGet(ctx context.Context, codeOwners *models.CodeOwners) *models.ErrorResponse {
+	err, rules := cs.repo.CodeOwnersGet(ctx)
+	if err != nil {
+		return err
+	}
+
+	codeOwners.Rules = rules
+
+	return nil
+}
+
+// Обязательные ревьюеры для набора измененных файлов: для каждого файла берется последнее подходящее правило
+func requiredReviewers(rules []models.CodeOwnerRule, changedFiles []string) models.RequiredReviewers {
+	required := models.RequiredReviewers{
+		UserIDs:       make([]string, 0),
+		TeamNames:     make([]string, 0),
+		LeadTeamNames: make([]string, 0),
+	}
+
+	matchers := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		matchers[i] = compileCodeOwnersPattern(rule.Pattern)
+	}
+
+	for _, file := range changedFiles {
+		file = strings.TrimPrefix(file, "/")
+
+		for i := len(rules) - 1; i >= 0; i-- {
+			if !matchers[i].MatchString(file) {
+				continue
+			}
+
+			rule := rules[i]
+			if rule.UserID != "" && !slices.Contains(required.UserIDs, rule.UserID) {
+				required.UserIDs = append(required.UserIDs, rule.UserID)
+			}
+			if rule.TeamName != "" && !slices.Contains(required.TeamNames, rule.TeamName) {
+				required.TeamNames = append(required.TeamNames, rule.TeamName)
+			}
+			if rule.TeamName != "" && rule.RequireLead && !slices.Contains(required.LeadTeamNames, rule.TeamName) {
+				required.LeadTeamNames = append(required.LeadTeamNames, rule.TeamName)
+			}
+			break
+		}
+	}
+
+	return required
+}
+
+// Шаблон в стиле CODEOWNERS:
+// - "*" - любые символы, кроме "/", "?" - один такой символ, "**" - любые символы, включая "/"
+// - шаблон без "/" (кроме завершающего) подходит на любой глубине, иначе - относительно корня
+// - шаблон подходит и к файлам внутри совпавшей директории
+func compileCodeOwnersPattern(pattern string) *regexp.Regexp {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+
+	expr.WriteString("(?:/.*)?$")
+
+	return regexp.MustCompile(expr.String())
+}
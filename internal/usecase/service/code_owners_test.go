@@ -0,0 +1,99 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestCompileCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		// Шаблон без "/" подходит на любой глубине
+		{"*.sql", "schema.sql", true},
+		{"*.sql", "migrations/02.up.sql", true},
+		{"*.sql", "schema.sql.bak", false},
+		{"Makefile", "build/Makefile", true},
+
+		// Шаблон с "/" - относительно корня
+		{"/cmd/main.go", "cmd/main.go", true},
+		{"cmd/main.go", "tools/cmd/main.go", false},
+		{"internal/*.go", "internal/app.go", true},
+		{"internal/*.go", "internal/api/app.go", false},
+
+		// Директория подходит вместе с файлами внутри
+		{"migrations/", "migrations/02.up.sql", true},
+		{"migrations/", "db/migrations/02.up.sql", true},
+		{"migrations", "migrations_old/01.sql", false},
+		{"internal/api", "internal/api/handlers/pr.go", true},
+
+		// "**" и "?"
+		{"internal/**/*.go", "internal/app.go", true},
+		{"internal/**/*.go", "internal/api/types/pr.go", true},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"v?.txt", "v1.txt", true},
+		{"v?.txt", "v10.txt", false},
+		{"a?b", "a/b", false},
+
+		// Спецсимволы регулярных выражений экранируются
+		{"file.go", "fileXgo", false},
+		{"(x)+.go", "(x)+.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.file, func(t *testing.T) {
+			if got := compileCodeOwnersPattern(tt.pattern).MatchString(tt.file); got != tt.want {
+				t.Errorf("compileCodeOwnersPattern(%q).MatchString(%q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredReviewers(t *testing.T) {
+	rules := []models.CodeOwnerRule{
+		{Pattern: "*", TeamName: "backend"},
+		{Pattern: "*.sql", UserID: "u1"},
+		{Pattern: "migrations/", TeamName: "dba", RequireLead: true},
+		{Pattern: "docs/", TeamName: "docs"},
+		{Pattern: "docs/api/", UserID: "u2"},
+	}
+
+	tests := []struct {
+		name         string
+		changedFiles []string
+		want         models.RequiredReviewers
+	}{
+		{
+			name:         "no files",
+			changedFiles: nil,
+			want:         models.RequiredReviewers{UserIDs: []string{}, TeamNames: []string{}, LeadTeamNames: []string{}},
+		},
+		{
+			name:         "last matching rule wins",
+			changedFiles: []string{"migrations/02.up.sql"},
+			want:         models.RequiredReviewers{UserIDs: []string{}, TeamNames: []string{"dba"}, LeadTeamNames: []string{"dba"}},
+		},
+		{
+			name:         "user rule",
+			changedFiles: []string{"/schema.sql"},
+			want:         models.RequiredReviewers{UserIDs: []string{"u1"}, TeamNames: []string{}, LeadTeamNames: []string{}},
+		},
+		{
+			name:         "deduplicated across files",
+			changedFiles: []string{"cmd/main.go", "internal/app.go", "docs/readme.md", "docs/api/openapi.yml", "docs/guide.md"},
+			want:         models.RequiredReviewers{UserIDs: []string{"u2"}, TeamNames: []string{"backend", "docs"}, LeadTeamNames: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requiredReviewers(rules, tt.changedFiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requiredReviewers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
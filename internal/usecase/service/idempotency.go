@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/repository"
+)
+
+// Сколько хранится ответ на запрос с Idempotency-Key (после этого ключ можно использовать заново)
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// Сколько ключ может оставаться зарезервированным без сохраненного ответа (например, если процесс упал
+// во время выполнения запроса); после этого ключ можно зарезервировать заново
+const IdempotencyLockTimeout = 5 * time.Minute
+
+type IdempotencyService struct {
+	repo repository.IdempotencyRepository
+}
+
+func NewIdempotencyService(repo repository.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{
+		repo: repo,
+	}
+}
+
+// Резервирует ключ под запрос; если ответ на ключ уже сохранен, он записывается в request
+func (is *IdempotencyService) IdempotencyBegin(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse {
+	return is.repo.IdempotencyBegin(ctx, request, IdempotencyKeyTTL, IdempotencyLockTimeout)
+}
+
+func (is *IdempotencyService) IdempotencySave(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse {
+	return is.repo.IdempotencySave(ctx, request)
+}
+
+// Снимает резерв с ключа (ответ не сохраняется, повтор запроса выполнится заново)
+func (is *IdempotencyService) IdempotencyRelease(ctx context.Context, request *models.IdempotentRequest) *models.ErrorResponse {
+	return is.repo.IdempotencyRelease(ctx, request)
+}
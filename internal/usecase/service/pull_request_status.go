@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+// Действия, меняющие статус пулл реквеста (по одному эндпоинту на каждое)
+const (
+	ActionReady  = "ready"
+	ActionClose  = "close"
+	ActionMerge  = "merge"
+	ActionReopen = "reopen"
+)
+
+type transition struct {
+	from []string
+	to   string
+}
+
+// Таблица переходов: из каких статусов допустимо действие и в какой статус оно переводит пулл реквест
+var pullRequestTransitions = map[string]transition{
+	ActionReady:  {from: []string{models.StatusDraft}, to: models.StatusOpen},
+	ActionClose:  {from: []string{models.StatusDraft, models.StatusOpen}, to: models.StatusClosed},
+	ActionMerge:  {from: []string{models.StatusOpen}, to: models.StatusMerged},
+	ActionReopen: {from: []string{models.StatusMerged, models.StatusClosed}, to: models.StatusOpen},
+}
+
+func checkTransition(action, status string) *models.ErrorResponse {
+	if slices.Contains(pullRequestTransitions[action].from, status) {
+		return nil
+	}
+
+	return &models.ErrorResponse{
+		Code:    codes.ErrInvalidTransition,
+		Message: fmt.Sprintf("cannot %s pull request in status %s", action, status),
+	}
+}
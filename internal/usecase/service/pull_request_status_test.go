@@ -0,0 +1,49 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestCheckTransition(t *testing.T) {
+	statuses := []string{models.StatusDraft, models.StatusOpen, models.StatusMerged, models.StatusClosed}
+
+	allowed := map[string][]string{
+		ActionReady:  {models.StatusDraft},
+		ActionClose:  {models.StatusDraft, models.StatusOpen},
+		ActionMerge:  {models.StatusOpen},
+		ActionReopen: {models.StatusMerged, models.StatusClosed},
+		"unknown":    {},
+	}
+
+	for action, from := range allowed {
+		for _, status := range statuses {
+			t.Run(action+" from "+status, func(t *testing.T) {
+				wantAllowed := slices.Contains(from, status)
+
+				errResp := checkTransition(action, status)
+				if wantAllowed && errResp != nil {
+					t.Errorf("checkTransition(%q, %q) = %+v, want nil", action, status, errResp)
+				} else if !wantAllowed && (errResp == nil || errResp.Code != codes.ErrInvalidTransition) {
+					t.Errorf("checkTransition(%q, %q) = %+v, want %s", action, status, errResp, codes.ErrInvalidTransition)
+				}
+			})
+		}
+	}
+}
+
+func TestReopenTransition(t *testing.T) {
+	// Переоткрытый пулл реквест снова становится OPEN, независимо от того, был он MERGED или CLOSED
+	if to := pullRequestTransitions[ActionReopen].to; to != models.StatusOpen {
+		t.Errorf("reopen leads to %q, want %q", to, models.StatusOpen)
+	}
+
+	for _, status := range []string{models.StatusMerged, models.StatusClosed} {
+		if errResp := checkTransition(ActionReopen, status); errResp != nil {
+			t.Errorf("checkTransition(%q, %q) = %+v, want nil", ActionReopen, status, errResp)
+		}
+	}
+}
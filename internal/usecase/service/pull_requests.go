@@ -3,41 +3,154 @@ package service
 import (
 	"context"
 
+	"github.com/tousart/avitotest/internal/codes"
 	"github.com/tousart/avitotest/internal/models"
 	"github.com/tousart/avitotest/internal/repository"
+	"github.com/tousart/avitotest/internal/usecase"
 )
 
 const (
 	TimeFormat      = "2006-01-02T15:04:05Z"
-	DefaultPRStatus = "OPEN"
+	DefaultPRStatus = models.StatusOpen
 )
 
 type PullRequestsService struct {
-	repo repository.PullRequestsRepository
+	repo           repository.PullRequestsRepository
+	codeOwnersRepo repository.CodeOwnersRepository
+	selector       usecase.ReviewerSelector
 }
 
-func NewPullRequestsService(repo repository.PullRequestsRepository) *PullRequestsService {
+func NewPullRequestsService(repo repository.PullRequestsRepository, codeOwnersRepo repository.CodeOwnersRepository, selector usecase.ReviewerSelector) *PullRequestsService {
 	return &PullRequestsService{
-		repo: repo,
+		repo:           repo,
+		codeOwnersRepo: codeOwnersRepo,
+		selector:       selector,
 	}
 }
 
 func (ps *PullRequestsService) PullRequestCreate(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
 	pullRequest.Status = DefaultPRStatus
 
-	err, createdAt, reviewers := ps.repo.PullRequestCreate(ctx, pullRequest)
+	// Черновику ревьюеры назначаются только при переводе в OPEN
+
+	var required models.RequiredReviewers
+	if pullRequest.Draft {
+		pullRequest.Status = models.StatusDraft
+	} else {
+		err, ownerRequired := ps.requiredReviewers(ctx, pullRequest.ChangedFiles)
+		if err != nil {
+			return err
+		}
+		required = ownerRequired
+	}
+
+	err, createdAt, reviewers, ownerReviewers, fallbackReviewers, version := ps.repo.PullRequestCreate(ctx, pullRequest, required, ps.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	pullRequest.AssignedReviewers = reviewers
+	pullRequest.OwnerReviewers = ownerReviewers
+	pullRequest.FallbackReviewers = fallbackReviewers
+	if !pullRequest.Draft {
+		pullRequest.ReviewerStrategy = ps.selector.Name()
+	}
+	pullRequest.CreatedAt = (*createdAt).Format(TimeFormat)
+	pullRequest.Version = version
+
+	return nil
+}
+
+// Обязательные ревьюеры по правилам владения кодом (если переданы измененные файлы)
+func (ps *PullRequestsService) requiredReviewers(ctx context.Context, changedFiles []string) (*models.ErrorResponse, models.RequiredReviewers) {
+	if len(changedFiles) == 0 {
+		return nil, models.RequiredReviewers{}
+	}
+
+	err, rules := ps.codeOwnersRepo.CodeOwnersGet(ctx)
+	if err != nil {
+		return err, models.RequiredReviewers{}
+	}
+
+	return nil, requiredReviewers(rules, changedFiles)
+}
+
+func (ps *PullRequestsService) PullRequestGet(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
+	err, createdAt, mergedAt, pullRequestName, authorID, status, reviewers, reviews, version := ps.repo.PullRequestGet(ctx, pullRequest)
 	if err != nil {
 		return err
 	}
 
+	pullRequest.PullRequestName = pullRequestName
+	pullRequest.AuthorID = authorID
+	pullRequest.Status = status
 	pullRequest.AssignedReviewers = reviewers
+	pullRequest.Reviews = reviews
+	pullRequest.Version = version
 	pullRequest.CreatedAt = (*createdAt).Format(TimeFormat)
+	if mergedAt != nil {
+		pullRequest.MergedAt = (*mergedAt).Format(TimeFormat)
+	}
+
+	return nil
+}
+
+func (ps *PullRequestsService) PullRequestList(ctx context.Context, filter *models.PullRequestFilter, list *models.PullRequestList) *models.ErrorResponse {
+	err, rows, hasMore := ps.repo.PullRequestList(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	list.PullRequests = make([]models.PullRequest, 0, len(rows))
+	for _, row := range rows {
+		pullRequest := row.PullRequest
+		pullRequest.CreatedAt = row.CreatedAt.Format(TimeFormat)
+		if row.MergedAt != nil {
+			pullRequest.MergedAt = (*row.MergedAt).Format(TimeFormat)
+		}
+
+		list.PullRequests = append(list.PullRequests, pullRequest)
+	}
+
+	// Курсор следующей страницы - последний пулл реквест текущей
+
+	if hasMore {
+		last := rows[len(rows)-1]
+		list.NextCursor = models.PullRequestCursor{
+			CreatedAt:     last.CreatedAt,
+			PullRequestID: last.PullRequest.PullRequestID,
+		}.Encode()
+	}
+
+	return nil
+}
+
+func (ps *PullRequestsService) PullRequestHistory(ctx context.Context, history *models.PullRequestHistory) *models.ErrorResponse {
+	err, events := ps.repo.PullRequestHistory(ctx, &models.PullRequest{PullRequestID: history.PullRequestID})
+	if err != nil {
+		return err
+	}
+
+	history.Events = events
 
 	return nil
 }
 
 func (ps *PullRequestsService) PullRequestMerge(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
-	err, createdAt, mergedAt, pullRequestName, authorID, status := ps.repo.PullRequestMerge(ctx, pullRequest)
+	err, status, _ := ps.repo.PullRequestState(ctx, pullRequest)
+	if err != nil {
+		return err
+	}
+
+	// Повторный merge идемпотентен, остальные переходы проверяются по таблице
+
+	if status != models.StatusMerged {
+		if err := checkTransition(ActionMerge, status); err != nil {
+			return err
+		}
+	}
+
+	err, createdAt, mergedAt, pullRequestName, authorID, status, version := ps.repo.PullRequestMerge(ctx, pullRequest)
 	if err != nil {
 		return err
 	}
@@ -47,12 +160,127 @@ func (ps *PullRequestsService) PullRequestMerge(ctx context.Context, pullRequest
 	pullRequest.Status = status
 	pullRequest.CreatedAt = (*createdAt).Format(TimeFormat)
 	pullRequest.MergedAt = (*mergedAt).Format(TimeFormat)
+	pullRequest.Version = version
+
+	return nil
+}
+
+func (ps *PullRequestsService) PullRequestReady(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
+	err, status, changedFiles := ps.repo.PullRequestState(ctx, pullRequest)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTransition(ActionReady, status); err != nil {
+		return err
+	}
+
+	err, required := ps.requiredReviewers(ctx, changedFiles)
+	if err != nil {
+		return err
+	}
+
+	err, ownerReviewers, fallbackReviewers := ps.repo.PullRequestReady(ctx, pullRequest, required, ps.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	if err := ps.PullRequestGet(ctx, pullRequest); err != nil {
+		return err
+	}
+
+	pullRequest.ChangedFiles = changedFiles
+	pullRequest.OwnerReviewers = ownerReviewers
+	pullRequest.FallbackReviewers = fallbackReviewers
+	pullRequest.ReviewerStrategy = ps.selector.Name()
+
+	return nil
+}
+
+func (ps *PullRequestsService) PullRequestClose(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
+	err, status, _ := ps.repo.PullRequestState(ctx, pullRequest)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTransition(ActionClose, status); err != nil {
+		return err
+	}
+
+	if err := ps.repo.PullRequestClose(ctx, pullRequest, status); err != nil {
+		return err
+	}
+
+	return ps.PullRequestGet(ctx, pullRequest)
+}
+
+func (ps *PullRequestsService) PullRequestReopen(ctx context.Context, pullRequest *models.PullRequest) *models.ErrorResponse {
+	err, status, changedFiles := ps.repo.PullRequestState(ctx, pullRequest)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTransition(ActionReopen, status); err != nil {
+		return err
+	}
+
+	// Ревьюеры проверяются с учетом текущих правил владения кодом
+
+	err, required := ps.requiredReviewers(ctx, changedFiles)
+	if err != nil {
+		return err
+	}
+
+	err, removedReviewers, fallbackReviewers := ps.repo.PullRequestReopen(ctx, pullRequest, status, required, ps.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	if err := ps.PullRequestGet(ctx, pullRequest); err != nil {
+		return err
+	}
+
+	pullRequest.RemovedReviewers = removedReviewers
+	pullRequest.FallbackReviewers = fallbackReviewers
+	pullRequest.ReviewerStrategy = ps.selector.Name()
 
 	return nil
 }
 
-func (ps *PullRequestsService) PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID string) *models.ErrorResponse {
-	err, pullRequestName, authorID, status, reviewers := ps.repo.PullRequestReassign(ctx, pullRequest, oldUserID)
+func (ps *PullRequestsService) PullRequestAddReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse {
+	if err := ps.repo.PullRequestAddReviewer(ctx, pullRequest, userID); err != nil {
+		return err
+	}
+
+	return ps.PullRequestGet(ctx, pullRequest)
+}
+
+func (ps *PullRequestsService) PullRequestRemoveReviewer(ctx context.Context, pullRequest *models.PullRequest, userID string) *models.ErrorResponse {
+	if err := ps.repo.PullRequestRemoveReviewer(ctx, pullRequest, userID); err != nil {
+		return err
+	}
+
+	return ps.PullRequestGet(ctx, pullRequest)
+}
+
+// Решение может оставить только сам ревьюер (инициатор из X-Actor-ID должен совпадать с user_id)
+func (ps *PullRequestsService) PullRequestReview(ctx context.Context, pullRequest *models.PullRequest, review *models.Review) *models.ErrorResponse {
+	if models.ActorFromContext(ctx) != review.UserID {
+		return &models.ErrorResponse{
+			Code:    codes.ErrForbidden,
+			Message: "only the reviewer can submit a verdict",
+		}
+	}
+
+	if err := ps.repo.PullRequestReview(ctx, pullRequest, review); err != nil {
+		return err
+	}
+
+	return ps.PullRequestGet(ctx, pullRequest)
+}
+
+func (ps *PullRequestsService) PullRequestReassign(ctx context.Context, pullRequest *models.PullRequest, oldUserID, newUserID string) *models.ErrorResponse {
+	err, pullRequestName, authorID, status, reviewers, fallbackReviewers, version := ps.repo.PullRequestReassign(ctx, pullRequest, oldUserID, newUserID, ps.selector.Select)
 	if err != nil {
 		return err
 	}
@@ -61,6 +289,11 @@ func (ps *PullRequestsService) PullRequestReassign(ctx context.Context, pullRequ
 	pullRequest.AuthorID = authorID
 	pullRequest.Status = status
 	pullRequest.AssignedReviewers = reviewers
+	pullRequest.FallbackReviewers = fallbackReviewers
+	pullRequest.Version = version
+	if newUserID == "" {
+		pullRequest.ReviewerStrategy = ps.selector.Name()
+	}
 
 	return nil
 }
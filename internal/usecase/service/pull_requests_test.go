@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/codes"
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func TestPullRequestReviewForbidden(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor string
+	}{
+		{"no actor", ""},
+		{"other user", "u2"},
+		{"author", "author"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.actor != "" {
+				ctx = models.WithActor(ctx, tt.actor)
+			}
+
+			// Проверка выполняется до обращения к репозиторию
+			service := &PullRequestsService{}
+			review := &models.Review{UserID: "u1", Verdict: "APPROVED"}
+
+			errResp := service.PullRequestReview(ctx, &models.PullRequest{PullRequestID: "pr-1"}, review)
+			if errResp == nil || errResp.Code != codes.ErrForbidden {
+				t.Errorf("PullRequestReview() by %q = %+v, want %s", tt.actor, errResp, codes.ErrForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/tousart/avitotest/internal/models"
+	"github.com/tousart/avitotest/internal/usecase"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+)
+
+// Выбор стратегии по названию (задается в конфигурации деплоя).
+// Любая стратегия сначала отдает предпочтение кандидатам с наибольшим количеством совпавших тегов.
+func NewReviewerSelector(strategy string) (usecase.ReviewerSelector, error) {
+	switch strategy {
+	case StrategyRandom:
+		return &TagMatchSelector{base: &RandomSelector{}}, nil
+	case StrategyRoundRobin:
+		return &TagMatchSelector{base: &RoundRobinSelector{}}, nil
+	case "", StrategyLeastLoaded:
+		return &TagMatchSelector{base: &LeastLoadedSelector{}}, nil
+	default:
+		return nil, fmt.Errorf("service: NewReviewerSelector: unknown strategy %q", strategy)
+	}
+}
+
+// Выбор с учетом тегов: кандидаты группируются по количеству совпавших тегов пулл реквеста,
+// и группы перебираются от большего совпадения к меньшему, внутри группы выбирает base.
+// Если совпадений нет ни у кого, это обычный выбор base.
+
+type TagMatchSelector struct {
+	base usecase.ReviewerSelector
+}
+
+func (s *TagMatchSelector) Name() string {
+	return s.base.Name()
+}
+
+func (s *TagMatchSelector) Select(candidates []models.ReviewerCandidate, count int) []string {
+	groups := make(map[int][]models.ReviewerCandidate)
+	matches := make([]int, 0)
+	for _, candidate := range candidates {
+		if _, ok := groups[candidate.MatchedTags]; !ok {
+			matches = append(matches, candidate.MatchedTags)
+		}
+		groups[candidate.MatchedTags] = append(groups[candidate.MatchedTags], candidate)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(matches)))
+
+	reviewers := make([]string, 0, count)
+	for _, matched := range matches {
+		if len(reviewers) >= count {
+			break
+		}
+
+		reviewers = append(reviewers, s.base.Select(groups[matched], count-len(reviewers))...)
+	}
+
+	return reviewers
+}
+
+// Случайный выбор
+
+type RandomSelector struct{}
+
+func (s *RandomSelector) Name() string {
+	return StrategyRandom
+}
+
+func (s *RandomSelector) Select(candidates []models.ReviewerCandidate, count int) []string {
+	return firstUserIDs(shuffled(candidates), count)
+}
+
+// Выбор по кругу: кандидаты упорядочиваются по user_id,
+// и выбор начинается со следующего после последнего назначенного
+
+type RoundRobinSelector struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (s *RoundRobinSelector) Name() string {
+	return StrategyRoundRobin
+}
+
+func (s *RoundRobinSelector) Select(candidates []models.ReviewerCandidate, count int) []string {
+	ordered := slices.Clone(candidates)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].UserID < ordered[j].UserID
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := sort.Search(len(ordered), func(i int) bool {
+		return ordered[i].UserID > s.last
+	})
+	ordered = append(ordered[start:], ordered[:start]...)
+
+	reviewers := firstUserIDs(ordered, count)
+	if len(reviewers) > 0 {
+		s.last = reviewers[len(reviewers)-1]
+	}
+
+	return reviewers
+}
+
+// Выбор наименее загруженных (по количеству OPEN пулл реквестов на ревью, поведение по умолчанию).
+// При равной загрузке кандидаты выбираются случайно.
+
+type LeastLoadedSelector struct{}
+
+func (s *LeastLoadedSelector) Name() string {
+	return StrategyLeastLoaded
+}
+
+func (s *LeastLoadedSelector) Select(candidates []models.ReviewerCandidate, count int) []string {
+	ordered := shuffled(candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].OpenReviews < ordered[j].OpenReviews
+	})
+
+	return firstUserIDs(ordered, count)
+}
+
+func shuffled(candidates []models.ReviewerCandidate) []models.ReviewerCandidate {
+	result := slices.Clone(candidates)
+	rand.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+
+	return result
+}
+
+func firstUserIDs(candidates []models.ReviewerCandidate, count int) []string {
+	count = min(count, len(candidates))
+
+	reviewers := make([]string, 0, count)
+	for _, candidate := range candidates[:count] {
+		reviewers = append(reviewers, candidate.UserID)
+	}
+
+	return reviewers
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/tousart/avitotest/internal/models"
+)
+
+func candidates(userIDs ...string) []models.ReviewerCandidate {
+	result := make([]models.ReviewerCandidate, 0, len(userIDs))
+	for _, userID := range userIDs {
+		result = append(result, models.ReviewerCandidate{UserID: userID})
+	}
+
+	return result
+}
+
+func TestNewReviewerSelector(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     string
+		wantErr  bool
+	}{
+		{"", StrategyLeastLoaded, false},
+		{StrategyRandom, StrategyRandom, false},
+		{StrategyRoundRobin, StrategyRoundRobin, false},
+		{StrategyLeastLoaded, StrategyLeastLoaded, false},
+		{"fastest", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			selector, err := NewReviewerSelector(tt.strategy)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewReviewerSelector(%q) error = nil, want error", tt.strategy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewReviewerSelector(%q) error = %v", tt.strategy, err)
+			}
+			if got := selector.Name(); got != tt.want {
+				t.Errorf("NewReviewerSelector(%q).Name() = %q, want %q", tt.strategy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomSelector(t *testing.T) {
+	pool := candidates("u1", "u2", "u3")
+
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{"none", 0, 0},
+		{"some", 2, 2},
+		{"more than candidates", 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := (&RandomSelector{}).Select(pool, tt.count)
+			if len(selected) != tt.want {
+				t.Fatalf("Select() = %v, want %d reviewers", selected, tt.want)
+			}
+
+			seen := make(map[string]struct{})
+			for _, userID := range selected {
+				if !slices.ContainsFunc(pool, func(c models.ReviewerCandidate) bool { return c.UserID == userID }) {
+					t.Errorf("Select() returned %q, not a candidate", userID)
+				}
+				if _, ok := seen[userID]; ok {
+					t.Errorf("Select() returned %q twice", userID)
+				}
+				seen[userID] = struct{}{}
+			}
+		})
+	}
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	selector := &RoundRobinSelector{}
+
+	// Кандидаты упорядочиваются по user_id независимо от порядка во входных данных,
+	// выбор продолжается после последнего назначенного и переходит через начало списка
+	steps := []struct {
+		candidates []models.ReviewerCandidate
+		count      int
+		want       []string
+	}{
+		{candidates("u3", "u1", "u2"), 1, []string{"u1"}},
+		{candidates("u1", "u2", "u3"), 1, []string{"u2"}},
+		{candidates("u2", "u3", "u1"), 2, []string{"u3", "u1"}},
+		{candidates("u1", "u3"), 1, []string{"u3"}},
+		{candidates("u1", "u2", "u3"), 5, []string{"u1", "u2", "u3"}},
+		{nil, 1, []string{}},
+		{candidates("u1", "u2", "u3"), 1, []string{"u1"}},
+	}
+
+	for i, step := range steps {
+		if got := selector.Select(step.candidates, step.count); !reflect.DeepEqual(got, step.want) {
+			t.Fatalf("step %d: Select() = %v, want %v", i, got, step.want)
+		}
+	}
+}
+
+func TestLeastLoadedSelector(t *testing.T) {
+	pool := []models.ReviewerCandidate{
+		{UserID: "u1", OpenReviews: 3},
+		{UserID: "u2", OpenReviews: 0},
+		{UserID: "u3", OpenReviews: 1},
+		{UserID: "u4", OpenReviews: 1},
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  [][]string // допустимые результаты (при равной загрузке выбор случайный)
+	}{
+		{"least loaded", 1, [][]string{{"u2"}}},
+		{"tie broken randomly", 2, [][]string{{"u2", "u3"}, {"u2", "u4"}}},
+		{"all ordered by load", 4, [][]string{{"u2", "u3", "u4", "u1"}, {"u2", "u4", "u3", "u1"}}},
+		{"more than candidates", 10, [][]string{{"u2", "u3", "u4", "u1"}, {"u2", "u4", "u3", "u1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				got := (&LeastLoadedSelector{}).Select(pool, tt.count)
+				if !slices.ContainsFunc(tt.want, func(want []string) bool { return slices.Equal(got, want) }) {
+					t.Fatalf("Select() = %v, want one of %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTagMatchSelector(t *testing.T) {
+	pool := []models.ReviewerCandidate{
+		{UserID: "u1", MatchedTags: 0},
+		{UserID: "u2", MatchedTags: 2},
+		{UserID: "u3", MatchedTags: 1},
+		{UserID: "u4", MatchedTags: 2},
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  []string
+	}{
+		{"best match first", 1, []string{"u2"}},
+		{"whole best group", 2, []string{"u2", "u4"}},
+		{"next group after best", 3, []string{"u2", "u4", "u3"}},
+		{"no matches left", 4, []string{"u2", "u4", "u3", "u1"}},
+		{"more than candidates", 10, []string{"u2", "u4", "u3", "u1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Внутри группы выбирает base (по кругу - детерминированно)
+			selector := &TagMatchSelector{base: &RoundRobinSelector{}}
+			if got := selector.Select(pool, tt.count); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
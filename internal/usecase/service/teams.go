@@ -5,33 +5,133 @@ import (
 
 	"github.com/tousart/avitotest/internal/models"
 	"github.com/tousart/avitotest/internal/repository"
+	"github.com/tousart/avitotest/internal/usecase"
+)
+
+const (
+	DefaultReviewersCount    = 2
+	DefaultRequiredApprovals = 1
 )
 
 type TeamsService struct {
-	repo repository.TeamsRepository
+	repo     repository.TeamsRepository
+	selector usecase.ReviewerSelector
 }
 
-func NewTeamsService(repo repository.TeamsRepository) *TeamsService {
+func NewTeamsService(repo repository.TeamsRepository, selector usecase.ReviewerSelector) *TeamsService {
 	return &TeamsService{
-		repo: repo,
+		repo:     repo,
+		selector: selector,
 	}
 }
 
 func (ts *TeamsService) TeamAdd(ctx context.Context, team *models.Team) *models.ErrorResponse {
-	err := ts.repo.TeamAdd(ctx, team)
+	if team.ReviewersCount == 0 {
+		team.ReviewersCount = DefaultReviewersCount
+	}
+
+	if team.RequiredApprovals == nil {
+		requiredApprovals := DefaultRequiredApprovals
+		team.RequiredApprovals = &requiredApprovals
+	}
+
+	if team.FallbackTeams == nil {
+		team.FallbackTeams = make([]string, 0)
+	}
+
+	if team.MemberConflict == "" {
+		team.MemberConflict = models.MemberConflictMove
+	}
+
+	for i := range team.Members {
+		if team.Members[i].Role == "" {
+			team.Members[i].Role = models.RoleMember
+		}
+	}
+
+	err, report := ts.repo.TeamAdd(ctx, team, ts.selector.Select)
 	if err != nil {
 		return err
 	}
+
+	team.Reassignment = report
+
 	return nil
 }
 
 func (ts *TeamsService) TeamGet(ctx context.Context, team *models.Team) *models.ErrorResponse {
-	err, members := ts.repo.TeamGet(ctx, team)
+	err, settings, members, absences := ts.repo.TeamGet(ctx, team)
 	if err != nil {
 		return err
 	}
 
+	team.ReviewersCount = settings.ReviewersCount
+	team.MaxOpenReviews = *settings.MaxOpenReviews
+	team.RequiredApprovals = settings.RequiredApprovals
+	team.FallbackTeams = settings.FallbackTeams
 	team.Members = members
+	team.Absences = absences
+	team.ArchivedAt = settings.ArchivedAt
+
+	return nil
+}
+
+func (ts *TeamsService) TeamSetSettings(ctx context.Context, settings *models.TeamSettings) *models.ErrorResponse {
+	err, updated := ts.repo.TeamSetSettings(ctx, settings)
+	if err != nil {
+		return err
+	}
+
+	(*settings) = *updated
+
+	return nil
+}
+
+func (ts *TeamsService) TeamDeactivate(ctx context.Context, deactivation *models.TeamDeactivation) *models.ErrorResponse {
+	err, deactivated, report := ts.repo.TeamDeactivate(ctx, deactivation, ts.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	deactivation.Deactivated = deactivated
+	deactivation.Reassignment = report
 
 	return nil
 }
+
+func (ts *TeamsService) TeamArchive(ctx context.Context, archive *models.TeamArchive) *models.ErrorResponse {
+	err, members, report, archivedAt := ts.repo.TeamArchive(ctx, archive, ts.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	if archive.MoveToTeam != "" {
+		archive.Moved = members
+	} else {
+		archive.Deactivated = members
+	}
+	archive.Reassignment = report
+	archive.ArchivedAt = archivedAt
+
+	return nil
+}
+
+// Разархивация возвращает команду в назначение ревьюеров (участники, переведенные или деактивированные при архивации, не возвращаются)
+func (ts *TeamsService) TeamUnarchive(ctx context.Context, team *models.Team) *models.ErrorResponse {
+	if err := ts.repo.TeamUnarchive(ctx, team); err != nil {
+		return err
+	}
+
+	return ts.TeamGet(ctx, team)
+}
+
+// Изменение роли участника, в ответе - команда целиком
+func (ts *TeamsService) TeamUpdateMember(ctx context.Context, update *models.TeamMemberUpdate, team *models.Team) *models.ErrorResponse {
+	if err := ts.repo.TeamUpdateMember(ctx, update); err != nil {
+		return err
+	}
+
+	team.TeamName = update.TeamName
+
+	return ts.TeamGet(ctx, team)
+}
@@ -5,26 +5,115 @@ import (
 
 	"github.com/tousart/avitotest/internal/models"
 	"github.com/tousart/avitotest/internal/repository"
+	"github.com/tousart/avitotest/internal/usecase"
 )
 
 type UsersService struct {
-	repo repository.UsersRepository
+	repo     repository.UsersRepository
+	selector usecase.ReviewerSelector
 }
 
-func NewUsersService(repo repository.UsersRepository) *UsersService {
+func NewUsersService(repo repository.UsersRepository, selector usecase.ReviewerSelector) *UsersService {
 	return &UsersService{
-		repo: repo,
+		repo:     repo,
+		selector: selector,
 	}
 }
 
+func (us *UsersService) GetUser(ctx context.Context, user *models.User) *models.ErrorResponse {
+	err, found := us.repo.GetUser(ctx, user.UserID)
+	if err != nil {
+		return err
+	}
+
+	(*user) = *found
+
+	return nil
+}
+
+func (us *UsersService) CreateUser(ctx context.Context, user *models.User) *models.ErrorResponse {
+	if err := us.repo.CreateUser(ctx, user); err != nil {
+		return err
+	}
+
+	return us.GetUser(ctx, user)
+}
+
+func (us *UsersService) UpdateUser(ctx context.Context, update *models.UserUpdate, user *models.User) *models.ErrorResponse {
+	if err := us.repo.UpdateUser(ctx, update); err != nil {
+		return err
+	}
+
+	user.UserID = update.UserID
+
+	return us.GetUser(ctx, user)
+}
+
+func (us *UsersService) ListUsers(ctx context.Context, filter *models.UserFilter, list *models.UserList) *models.ErrorResponse {
+	err, users, hasMore := us.repo.ListUsers(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	list.Users = users
+	if hasMore {
+		list.NextCursor = models.EncodeUserCursor(users[len(users)-1].UserID)
+	}
+
+	return nil
+}
+
 func (us *UsersService) SetIsActive(ctx context.Context, user *models.User) *models.ErrorResponse {
-	err, username, teamName := us.repo.SetIsActive(ctx, user)
+	err, username, teamName, report := us.repo.SetIsActive(ctx, user, us.selector.Select)
 	if err != nil {
 		return err
 	}
 
 	user.Username = username
 	user.TeamName = teamName
+	user.Reassignment = report
+
+	return nil
+}
+
+func (us *UsersService) MoveTeam(ctx context.Context, user *models.User) *models.ErrorResponse {
+	err, username, isActive, memberships, report := us.repo.MoveTeam(ctx, user, us.selector.Select)
+	if err != nil {
+		return err
+	}
+
+	user.Username = username
+	user.IsActive = isActive
+	user.Memberships = memberships
+	user.Reassignment = report
+
+	return nil
+}
+
+// Дополнительное членство в команде (основная команда не меняется)
+func (us *UsersService) JoinTeam(ctx context.Context, user *models.User) *models.ErrorResponse {
+	if err := us.repo.JoinTeam(ctx, user); err != nil {
+		return err
+	}
+
+	return us.GetUser(ctx, user)
+}
+
+func (us *UsersService) LeaveTeam(ctx context.Context, user *models.User) *models.ErrorResponse {
+	if err := us.repo.LeaveTeam(ctx, user); err != nil {
+		return err
+	}
+
+	return us.GetUser(ctx, user)
+}
+
+func (us *UsersService) AddAbsence(ctx context.Context, absence *models.Absence) *models.ErrorResponse {
+	err, absenceID := us.repo.AddAbsence(ctx, absence)
+	if err != nil {
+		return err
+	}
+
+	absence.AbsenceID = absenceID
 
 	return nil
 }
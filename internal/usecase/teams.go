@@ -9,4 +9,9 @@ import (
 type TeamsService interface {
 	TeamAdd(ctx context.Context, team *models.Team) *models.ErrorResponse
 	TeamGet(ctx context.Context, team *models.Team) *models.ErrorResponse
+	TeamSetSettings(ctx context.Context, settings *models.TeamSettings) *models.ErrorResponse
+	TeamDeactivate(ctx context.Context, deactivation *models.TeamDeactivation) *models.ErrorResponse
+	TeamArchive(ctx context.Context, archive *models.TeamArchive) *models.ErrorResponse
+	TeamUnarchive(ctx context.Context, team *models.Team) *models.ErrorResponse
+	TeamUpdateMember(ctx context.Context, update *models.TeamMemberUpdate, team *models.Team) *models.ErrorResponse
 }
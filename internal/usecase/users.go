@@ -7,7 +7,15 @@ import (
 )
 
 type UsersService interface {
+	GetUser(ctx context.Context, user *models.User) *models.ErrorResponse
+	CreateUser(ctx context.Context, user *models.User) *models.ErrorResponse
+	UpdateUser(ctx context.Context, update *models.UserUpdate, user *models.User) *models.ErrorResponse
+	ListUsers(ctx context.Context, filter *models.UserFilter, list *models.UserList) *models.ErrorResponse
 	SetIsActive(ctx context.Context, user *models.User) *models.ErrorResponse
+	MoveTeam(ctx context.Context, user *models.User) *models.ErrorResponse
+	JoinTeam(ctx context.Context, user *models.User) *models.ErrorResponse
+	LeaveTeam(ctx context.Context, user *models.User) *models.ErrorResponse
+	AddAbsence(ctx context.Context, absence *models.Absence) *models.ErrorResponse
 	GetReview(ctx context.Context, pullRequests *[]models.PullRequestShort, userID string) *models.ErrorResponse
 	GetActivity(ctx context.Context, usersActivity *[]models.UserActivity) *models.ErrorResponse
 }
@@ -0,0 +1,5 @@
+-- +migrate Up
+
+ALTER TABLE teams
+    ADD COLUMN reviewers_count INTEGER NOT NULL DEFAULT 2
+        CONSTRAINT teams_reviewers_count_positive CHECK (reviewers_count > 0);
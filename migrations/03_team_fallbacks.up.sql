@@ -0,0 +1,11 @@
+-- +migrate Up
+
+CREATE TABLE team_fallbacks (
+    team_name VARCHAR(64) NOT NULL REFERENCES teams(team_name)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    fallback_team_name VARCHAR(64) NOT NULL REFERENCES teams(team_name)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (team_name, fallback_team_name),
+    CONSTRAINT team_fallbacks_not_self CHECK (team_name <> fallback_team_name)
+);
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+CREATE TABLE code_owners (
+    position INTEGER PRIMARY KEY,
+    pattern VARCHAR(256) NOT NULL,
+    team_name VARCHAR(64) REFERENCES teams(team_name)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    user_id VARCHAR(64) REFERENCES users(user_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT code_owners_single_owner CHECK ((team_name IS NULL) <> (user_id IS NULL))
+);
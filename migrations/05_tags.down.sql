@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS pr_tags;
+
+DROP TABLE IF EXISTS user_tags;
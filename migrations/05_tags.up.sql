@@ -0,0 +1,15 @@
+-- +migrate Up
+
+CREATE TABLE user_tags (
+    user_id VARCHAR(64) NOT NULL REFERENCES users(user_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (user_id, tag)
+);
+
+CREATE TABLE pr_tags (
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_requests(pull_request_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (pull_request_id, tag)
+);
@@ -0,0 +1,12 @@
+-- +migrate Up
+
+CREATE TABLE user_absences (
+    absence_id SERIAL PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(user_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT user_absences_period CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX user_absences_user_id_ends_at_idx ON user_absences (user_id, ends_at);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE users DROP COLUMN IF EXISTS max_open_reviews;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS max_open_reviews;
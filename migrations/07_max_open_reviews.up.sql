@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE teams
+    ADD COLUMN max_open_reviews INTEGER
+        CONSTRAINT teams_max_open_reviews_positive CHECK (max_open_reviews > 0);
+
+ALTER TABLE users
+    ADD COLUMN max_open_reviews INTEGER
+        CONSTRAINT users_max_open_reviews_positive CHECK (max_open_reviews > 0);
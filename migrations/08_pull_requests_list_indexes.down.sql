@@ -0,0 +1,12 @@
+-- +migrate Down
+DROP INDEX IF EXISTS users_team_name_idx;
+
+DROP INDEX IF EXISTS pr_reviewers_user_id_idx;
+
+DROP INDEX IF EXISTS pull_requests_merged_at_idx;
+
+DROP INDEX IF EXISTS pull_requests_author_id_created_at_idx;
+
+DROP INDEX IF EXISTS pull_requests_status_created_at_idx;
+
+DROP INDEX IF EXISTS pull_requests_created_at_id_idx;
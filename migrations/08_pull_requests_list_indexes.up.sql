@@ -0,0 +1,13 @@
+-- +migrate Up
+
+CREATE INDEX pull_requests_created_at_id_idx ON pull_requests (created_at, pull_request_id);
+
+CREATE INDEX pull_requests_status_created_at_idx ON pull_requests (status, created_at, pull_request_id);
+
+CREATE INDEX pull_requests_author_id_created_at_idx ON pull_requests (author_id, created_at, pull_request_id);
+
+CREATE INDEX pull_requests_merged_at_idx ON pull_requests (merged_at) WHERE merged_at IS NOT NULL;
+
+CREATE INDEX pr_reviewers_user_id_idx ON pr_reviewers (user_id);
+
+CREATE INDEX users_team_name_idx ON users (team_name);
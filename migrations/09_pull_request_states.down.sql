@@ -0,0 +1,15 @@
+-- +migrate Down
+DROP TABLE IF EXISTS pr_files;
+
+UPDATE pull_requests SET status = 'OPEN' WHERE status::text IN ('DRAFT', 'CLOSED');
+
+ALTER TYPE status_enum RENAME TO status_enum_old;
+
+CREATE TYPE status_enum AS ENUM ('OPEN', 'MERGED');
+
+ALTER TABLE pull_requests
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN status TYPE status_enum USING status::text::status_enum,
+    ALTER COLUMN status SET DEFAULT 'OPEN';
+
+DROP TYPE status_enum_old;
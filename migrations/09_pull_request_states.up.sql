@@ -0,0 +1,12 @@
+-- +migrate Up
+
+ALTER TYPE status_enum ADD VALUE IF NOT EXISTS 'DRAFT';
+
+ALTER TYPE status_enum ADD VALUE IF NOT EXISTS 'CLOSED';
+
+CREATE TABLE pr_files (
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_requests(pull_request_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    PRIMARY KEY (pull_request_id, path)
+);
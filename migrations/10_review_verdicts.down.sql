@@ -0,0 +1,8 @@
+-- +migrate Down
+ALTER TABLE teams DROP COLUMN IF EXISTS required_approvals;
+
+ALTER TABLE pr_reviewers
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS verdict;
+
+DROP TYPE IF EXISTS verdict_enum;
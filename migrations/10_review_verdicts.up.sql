@@ -0,0 +1,13 @@
+-- +migrate Up
+
+CREATE TYPE verdict_enum AS ENUM ('APPROVED', 'CHANGES_REQUESTED');
+
+ALTER TABLE pr_reviewers
+    ADD COLUMN verdict verdict_enum,
+    ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE;
+
+-- Существующие команды получают 0, чтобы уже открытые пулл реквесты без решений можно было смерджить,
+-- как до этой миграции. Для новых команд значение по умолчанию (1) задает сервис в /team/add
+ALTER TABLE teams
+    ADD COLUMN required_approvals INTEGER NOT NULL DEFAULT 0
+        CONSTRAINT teams_required_approvals_not_negative CHECK (required_approvals >= 0);
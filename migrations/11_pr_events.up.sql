@@ -0,0 +1,17 @@
+-- +migrate Up
+
+CREATE TABLE pr_events (
+    pr_event_id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_requests(pull_request_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    user_id VARCHAR(64),
+    old_user_id VARCHAR(64),
+    from_status VARCHAR(16),
+    to_status VARCHAR(16),
+    verdict VARCHAR(32),
+    actor VARCHAR(64),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX pr_events_pull_request_id_idx ON pr_events (pull_request_id, pr_event_id);
@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- +migrate Up
+
+ALTER TABLE pull_requests
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
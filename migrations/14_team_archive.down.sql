@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE teams DROP COLUMN IF EXISTS archived_at;
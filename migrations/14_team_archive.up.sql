@@ -0,0 +1,3 @@
+-- +migrate Up
+
+ALTER TABLE teams ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
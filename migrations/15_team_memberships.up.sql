@@ -0,0 +1,21 @@
+-- +migrate Up
+
+CREATE TABLE team_memberships (
+    team_membership_id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL REFERENCES users(user_id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    team_name VARCHAR(64) NOT NULL REFERENCES teams(team_name)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    left_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT team_memberships_period CHECK (left_at IS NULL OR left_at >= joined_at)
+);
+
+-- У пользователя одно текущее членство
+CREATE UNIQUE INDEX team_memberships_current_idx ON team_memberships (user_id) WHERE left_at IS NULL;
+
+CREATE INDEX team_memberships_user_id_idx ON team_memberships (user_id, joined_at);
+
+-- Текущие команды существующих пользователей (время вступления неизвестно)
+INSERT INTO team_memberships (user_id, team_name)
+SELECT user_id, team_name FROM users;
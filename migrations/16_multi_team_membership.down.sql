@@ -0,0 +1,11 @@
+-- +migrate Down
+DROP INDEX IF EXISTS team_memberships_team_name_idx;
+
+DROP INDEX IF EXISTS team_memberships_current_idx;
+
+-- Дополнительные членства закрываются, остается только основная команда
+UPDATE team_memberships m SET left_at = NOW()
+FROM users u
+WHERE u.user_id = m.user_id AND m.left_at IS NULL AND m.team_name <> u.team_name;
+
+CREATE UNIQUE INDEX team_memberships_current_idx ON team_memberships (user_id) WHERE left_at IS NULL;
@@ -0,0 +1,8 @@
+-- +migrate Up
+
+-- Пользователь может одновременно состоять в нескольких командах (users.team_name - основная команда)
+DROP INDEX IF EXISTS team_memberships_current_idx;
+
+CREATE UNIQUE INDEX team_memberships_current_idx ON team_memberships (user_id, team_name) WHERE left_at IS NULL;
+
+CREATE INDEX team_memberships_team_name_idx ON team_memberships (team_name) WHERE left_at IS NULL;
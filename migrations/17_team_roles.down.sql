@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE code_owners DROP COLUMN IF EXISTS require_lead;
+
+ALTER TABLE team_memberships DROP COLUMN IF EXISTS role;
@@ -0,0 +1,9 @@
+-- +migrate Up
+
+-- Роль участника в команде: lead - может выбирать ревьюера вручную и требоваться правилами владения кодом,
+-- member - обычный участник, observer - не назначается ревьюером автоматически
+ALTER TABLE team_memberships ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'member'
+    CONSTRAINT team_memberships_role CHECK (role IN ('lead', 'member', 'observer'));
+
+-- Из команды-владельца назначается лид
+ALTER TABLE code_owners ADD COLUMN require_lead BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+-- +migrate Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_max_open_reviews_not_negative;
+
+UPDATE users SET max_open_reviews = NULL WHERE max_open_reviews = 0;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_max_open_reviews_positive CHECK (max_open_reviews > 0);
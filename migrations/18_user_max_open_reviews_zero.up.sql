@@ -0,0 +1,7 @@
+-- +migrate Up
+
+-- Собственный лимит пользователя: NULL - лимит команды, 0 - пользователь не назначается ревьюером автоматически
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_max_open_reviews_positive;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_max_open_reviews_not_negative CHECK (max_open_reviews >= 0);